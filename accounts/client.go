@@ -2,24 +2,43 @@ package accounts
 
 import (
 	"context"
+	errs "errors"
 	"time"
 
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
 	"github.com/aabri-assignments/form3-accounts/v1/accounts/models"
 	"github.com/aabri-assignments/form3-accounts/v1/accounts/retry"
 	"github.com/aabri-assignments/form3-accounts/v1/accounts/transport"
 	"github.com/aabri-assignments/form3-accounts/v1/accounts/transport/http"
 	"github.com/aabri-assignments/form3-accounts/v1/accounts/utils"
+	"github.com/aabri-assignments/form3-accounts/v1/pkg/iban"
 	"github.com/aabri-assignments/form3-accounts/v1/pkg/logging"
 )
 
-const basePath = "/v1/organisation/accounts/"
+const (
+	basePath                  = "/v1/organisation/accounts/"
+	accountType               = "accounts"
+	defaultMaxConflictRetries = 3
+)
 
 type Client interface {
 	Create(account *models.AccountData) (*models.AccountData, error)
 	Fetch(accountID string) (*models.AccountData, error)
 	Delete(accountID string, version int64) error
-	GetRetry() retry.Retrier
-	SetRetry(r retry.Retrier)
+	CreateWithContext(ctx context.Context, account *models.AccountData) (*models.AccountData, error)
+	FetchWithContext(ctx context.Context, accountID string) (*models.AccountData, error)
+	DeleteWithContext(ctx context.Context, accountID string, version int64) error
+	DeleteLatest(ctx context.Context, accountID string, opts DeleteLatestOptions) error
+	CreateWithDocument(ctx context.Context, account *models.AccountData) (*models.AccountData, *utils.Document, error)
+	FetchWithDocument(ctx context.Context, accountID string) (*models.AccountData, *utils.Document, error)
+	UpdateWithDocument(ctx context.Context, accountID string, version int64, attributes *models.AccountAttributesUpdate) (*models.AccountData, *utils.Document, error)
+	Update(accountID string, version int64, attributes *models.AccountAttributesUpdate) (*models.AccountData, error)
+	UpdateWithContext(ctx context.Context, accountID string, version int64, attributes *models.AccountAttributesUpdate) (*models.AccountData, error)
+	List(req *utils.ListAccountsRequest) (*utils.ListAccountsResponse, error)
+	ListWithContext(ctx context.Context, req *utils.ListAccountsRequest) (*utils.ListAccountsResponse, error)
+	Walk(ctx context.Context, req *utils.ListAccountsRequest, fn func(account *models.AccountData) error) error
+	GetRetry() retry.Policy
+	SetRetry(p retry.Policy)
 	GetTransport() transport.Transport
 	SetTransport(t transport.Transport)
 	GetLogger() logging.LeveledLogger
@@ -27,77 +46,453 @@ type Client interface {
 }
 
 type Options struct {
-	BaseURL      string
+	BaseURL string
+	// Duration is the time budget of an operation across all of its attempts, whatever the retry strategy,
+	// retry.DefaultMaxElapsedTime when zero.
 	Duration     time.Duration
 	Retries      int
 	InitialDelay time.Duration
 	Multiplier   int
 	Factor       float64
 	LogLevel     logging.Level
+	// DisableValidation skips the client-side validation of accounts before they are created.
+	DisableValidation bool
+	// GenerateIBAN fills in the IBAN of accounts created without one, from their bank details.
+	GenerateIBAN bool
+	// Timeout bounds every operation, including all of its retries, zero means no limit.
+	Timeout time.Duration
+	// HTTP configures the HTTP client, its connection pool and the timeout of a single attempt.
+	HTTP http.Options
+	// RetryClassifier decides which errors are retried, retry.DefaultClassifier when nil.
+	RetryClassifier retry.Classifier
+	// MaxRetryAfter caps the delays the API asks for with Retry-After, retry.DefaultMaxRetryAfter when zero.
+	MaxRetryAfter time.Duration
+	// RetryStrategy selects the retry policy by name, one of the retry.Strategy constants. It grows from
+	// InitialDelay, and the exponential backoff configured by the fields above is used when empty.
+	RetryStrategy string
+	// MaxDelay caps the delay before each retry, zero means no cap.
+	MaxDelay time.Duration
+	// RetryPolicy is the retry policy of the client, it takes precedence over RetryStrategy.
+	RetryPolicy retry.Policy
+}
+
+// DeleteLatestOptions configures AccountClient.DeleteLatest.
+type DeleteLatestOptions struct {
+	// MaxConflictRetries bounds how many times the account is re-fetched after a version conflict.
+	MaxConflictRetries int
+	// IgnoreNotFound makes deleting an account that does not exist a success.
+	IgnoreNotFound bool
 }
+
 type AccountClient struct {
 	Transport transport.Transport
-	Retry     retry.Retrier
-	Logger    logging.LeveledLogger
+	// Retry is the retry policy of every operation, each call gets its own retry state from it.
+	Retry  retry.Policy
+	Logger logging.LeveledLogger
+	// ValidateAccounts runs models.AccountData.Validate before an account is sent to the API.
+	ValidateAccounts bool
+	// GenerateIBAN fills in the IBAN of accounts created without one.
+	GenerateIBAN bool
+	// Timeout bounds every operation, including all of its retries, zero means no limit.
+	Timeout time.Duration
+	// Classifier decides which errors are retried, retry.DefaultClassifier when nil.
+	Classifier retry.Classifier
+	// MaxRetryAfter caps the delays the API asks for with Retry-After, retry.DefaultMaxRetryAfter when zero.
+	MaxRetryAfter time.Duration
+	// MaxElapsedTime bounds the time an operation spends across all of its attempts, the budget of the
+	// retry policy when zero.
+	MaxElapsedTime time.Duration
+	// Now returns the time MaxElapsedTime is measured with, time.Now when nil.
+	Now func() time.Time
 }
 
+// NewRetryPolicy returns the retry policy the options describe. It fails when RetryStrategy is unknown.
+func (opt Options) NewRetryPolicy() (retry.Policy, error) {
+	switch {
+	case opt.RetryPolicy != nil:
+		return opt.RetryPolicy, nil
+	case opt.RetryStrategy == "" || opt.RetryStrategy == retry.StrategyExponential:
+		backOff := retry.NewExponentialBackOff(opt.Duration, opt.Retries, opt.InitialDelay, float64(opt.Multiplier), opt.Factor)
+		backOff.MaxDelay = opt.MaxDelay
+
+		return backOff, nil
+	default:
+		return retry.NewStrategy(opt.RetryStrategy, retry.StrategyOptions{
+			MaxRetries: opt.Retries,
+			BaseDelay:  opt.InitialDelay,
+			MaxDelay:   opt.MaxDelay,
+		})
+	}
+}
+
+// New creates a client for the accounts API. An unknown RetryStrategy is logged and replaced by the
+// exponential backoff, form3.NewForm3 rejects it up front.
 func New(opt Options) Client {
-	httpTransport := http.New(opt.BaseURL, basePath)
-	backOff := retry.NewExponentialBackOff(opt.Duration, opt.Retries, opt.InitialDelay, float64(opt.Multiplier), opt.Factor)
+	httpTransport := http.NewWithOptions(opt.BaseURL, basePath, opt.HTTP)
+	logger := &logging.Leveled{
+		Level: opt.LogLevel,
+	}
+
+	policy, err := opt.NewRetryPolicy()
+	if err != nil {
+		logger.Errorf("Using the exponential backoff: %v", err)
+
+		policy = retry.NewExponentialBackOff(opt.Duration, opt.Retries, opt.InitialDelay, float64(opt.Multiplier), opt.Factor)
+	}
+
+	// The budget applies to every strategy, not only to the exponential backoff that carries its own.
+	maxElapsedTime := opt.Duration
+	if maxElapsedTime <= 0 {
+		maxElapsedTime = retry.DefaultMaxElapsedTime
+		if budgeted, ok := policy.(retry.Budgeted); ok && budgeted.ElapsedTimeBudget() > 0 {
+			maxElapsedTime = budgeted.ElapsedTimeBudget()
+		}
+	}
 
 	return &AccountClient{
-		Transport: httpTransport,
-		Retry:     backOff,
-		Logger: &logging.Leveled{
-			Level: opt.LogLevel,
-		},
+		Transport:        httpTransport,
+		Retry:            policy,
+		Logger:           logger,
+		ValidateAccounts: !opt.DisableValidation,
+		GenerateIBAN:     opt.GenerateIBAN,
+		Timeout:          opt.Timeout,
+		Classifier:       opt.RetryClassifier,
+		MaxRetryAfter:    opt.MaxRetryAfter,
+		MaxElapsedTime:   maxElapsedTime,
 	}
 }
 func (c *AccountClient) Create(account *models.AccountData) (*models.AccountData, error) {
+	return c.CreateWithContext(context.Background(), account)
+}
+func (c *AccountClient) Fetch(accountID string) (*models.AccountData, error) {
+	return c.FetchWithContext(context.Background(), accountID)
+}
+func (c *AccountClient) Delete(accountID string, version int64) error {
+	return c.DeleteWithContext(context.Background(), accountID, version)
+}
+
+// CreateWithContext creates an account, retrying until the operation succeeds or ctx is done.
+func (c *AccountClient) CreateWithContext(ctx context.Context, account *models.AccountData) (*models.AccountData, error) {
+	created, _, err := c.CreateWithDocument(ctx, account)
+
+	return created, err
+}
+
+// CreateWithDocument creates an account like CreateWithContext and also returns the links and meta of the response.
+func (c *AccountClient) CreateWithDocument(ctx context.Context, account *models.AccountData) (*models.AccountData, *utils.Document, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if account == nil {
+		return nil, nil, &errors.ErrBadRequest{Detail: "no account to create"}
+	}
+
+	if c.GenerateIBAN {
+		account = c.withIBAN(account)
+	}
+
+	if c.ValidateAccounts {
+		if err := account.Validate(); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	req := &utils.CreateAccountRequest{Data: *account}
 
 	var resp *utils.CreateAccountResponse
 
 	operation := func() error {
 		var err error
-		resp, err = c.GetTransport().Create(context.Background(), req)
+		resp, err = c.GetTransport().Create(ctx, req)
 
 		return err
 	}
 
-	if err := retry.Retry(operation, c.Retry, c.Logger); err != nil {
-		return nil, err
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, nil, err
 	}
 
-	return &resp.Data, nil
+	return &resp.Data, &resp.Document, nil
 }
-func (c *AccountClient) Fetch(accountID string) (*models.AccountData, error) {
+
+// withIBAN returns a copy of the account with a generated IBAN when it has none, the caller's account is left untouched.
+func (c *AccountClient) withIBAN(account *models.AccountData) *models.AccountData {
+	if account.Attributes == nil || account.Attributes.Iban != "" || account.Attributes.Country == nil {
+		return account
+	}
+
+	attributes := *account.Attributes
+
+	generated, err := iban.Generate(*attributes.Country, attributes.BankID, attributes.Bic, attributes.AccountNumber)
+	if err != nil {
+		c.Logger.Debugf("Not generating an IBAN for account %s: %v", account.ID, err)
+
+		return account
+	}
+
+	attributes.Iban = generated
+	withIBAN := *account
+	withIBAN.Attributes = &attributes
+
+	return &withIBAN
+}
+
+// FetchWithContext fetches an account, retrying until the operation succeeds or ctx is done.
+func (c *AccountClient) FetchWithContext(ctx context.Context, accountID string) (*models.AccountData, error) {
+	account, _, err := c.FetchWithDocument(ctx, accountID)
+
+	return account, err
+}
+
+// FetchWithDocument fetches an account like FetchWithContext and also returns the links and meta of the response.
+func (c *AccountClient) FetchWithDocument(ctx context.Context, accountID string) (*models.AccountData, *utils.Document, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	var resp *utils.FetchAccountResponse
 
 	operation := func() error {
 		var err error
 
-		resp, err = c.GetTransport().Fetch(context.Background(), accountID)
+		resp, err = c.GetTransport().Fetch(ctx, accountID)
 
 		return err
 	}
 
-	if err := retry.Retry(operation, c.Retry, c.Logger); err != nil {
-		return nil, err
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, nil, err
 	}
 
-	return &resp.Data, nil
+	return &resp.Data, &resp.Document, nil
 }
-func (c *AccountClient) Delete(accountID string, version int64) error {
+
+// DeleteWithContext deletes an account, retrying until the operation succeeds or ctx is done.
+func (c *AccountClient) DeleteWithContext(ctx context.Context, accountID string, version int64) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	req := &utils.DeleteAccountRequest{ID: accountID, Version: version}
 
 	operation := func() error {
 		c.Logger.Infof("Attempting operation...")
 
-		return c.Transport.Delete(context.Background(), req)
+		return c.Transport.Delete(ctx, req)
+	}
+
+	return c.retry(ctx, operation)
+}
+
+// DeleteLatest deletes whatever version of the account is current. When the account changes between
+// the fetch and the delete, the conflict is retried with a fresh version. Conflicts and the other retried
+// errors share the retry loop of the client, its policy, Retry-After delays and MaxElapsedTime budget.
+// A missing account is not retried.
+func (c *AccountClient) DeleteLatest(ctx context.Context, accountID string, opts DeleteLatestOptions) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	maxConflictRetries := opts.MaxConflictRetries
+	if maxConflictRetries == 0 {
+		maxConflictRetries = defaultMaxConflictRetries
+	}
+
+	conflicts := 0
+
+	operation := func() error {
+		resp, err := c.GetTransport().Fetch(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		var version int64
+		if resp.Data.Version != nil {
+			version = *resp.Data.Version
+		}
+
+		err = c.GetTransport().Delete(ctx, &utils.DeleteAccountRequest{ID: accountID, Version: version})
+
+		var conflictErr *errors.ErrConflict
+		if errs.As(err, &conflictErr) {
+			conflicts++
+			c.Logger.Infof("Account %s changed before it was deleted, retrying..", accountID)
+		}
+
+		return err
+	}
+
+	classifier := c.Classifier
+	if classifier == nil {
+		classifier = retry.DefaultClassifier
+	}
+
+	deleteClassifier := retry.ClassifierFunc(func(err error) bool {
+		var (
+			conflictErr *errors.ErrConflict
+			notFoundErr *errors.ErrNotFound
+		)
+
+		switch {
+		case errs.As(err, &conflictErr):
+			return conflicts <= maxConflictRetries
+		case errs.As(err, &notFoundErr):
+			return false
+		default:
+			return classifier.Retryable(err)
+		}
+	})
+
+	err := c.retryWithClassifier(ctx, operation, deleteClassifier)
+
+	var notFoundErr *errors.ErrNotFound
+	if opts.IgnoreNotFound && errs.As(err, &notFoundErr) {
+		c.Logger.Debugf("Account %s is already deleted", accountID)
+
+		return nil
+	}
+
+	return err
+}
+func (c *AccountClient) Update(accountID string, version int64, attributes *models.AccountAttributesUpdate) (*models.AccountData, error) {
+	return c.UpdateWithContext(context.Background(), accountID, version, attributes)
+}
+
+// UpdateWithContext patches the changed attributes of the given version of an account,
+// a version mismatch is returned as *errors.ErrConflict without retrying.
+func (c *AccountClient) UpdateWithContext(ctx context.Context, accountID string, version int64, attributes *models.AccountAttributesUpdate) (*models.AccountData, error) {
+	account, _, err := c.UpdateWithDocument(ctx, accountID, version, attributes)
+
+	return account, err
+}
+
+// UpdateWithDocument updates an account like UpdateWithContext and also returns the links and meta of the response.
+func (c *AccountClient) UpdateWithDocument(ctx context.Context, accountID string, version int64, attributes *models.AccountAttributesUpdate) (*models.AccountData, *utils.Document, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if attributes == nil {
+		return nil, nil, &errors.ErrBadRequest{Detail: "no attributes to update"}
+	}
+
+	req := &utils.UpdateAccountRequest{
+		Data: utils.UpdateAccountData{
+			ID:         accountID,
+			Type:       accountType,
+			Version:    version,
+			Attributes: *attributes,
+		},
+	}
+
+	var resp *utils.UpdateAccountResponse
+
+	operation := func() error {
+		var err error
+
+		resp, err = c.GetTransport().Update(ctx, req)
+
+		return err
+	}
+
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, nil, err
+	}
+
+	return &resp.Data, &resp.Document, nil
+}
+func (c *AccountClient) List(req *utils.ListAccountsRequest) (*utils.ListAccountsResponse, error) {
+	return c.ListWithContext(context.Background(), req)
+}
+
+// ListWithContext fetches a single page of accounts, retrying until the operation succeeds or ctx is done.
+// An invalid filter is rejected before any request is sent.
+func (c *AccountClient) ListWithContext(ctx context.Context, req *utils.ListAccountsRequest) (*utils.ListAccountsResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if req == nil {
+		req = &utils.ListAccountsRequest{}
+	}
+
+	if err := req.Filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	var resp *utils.ListAccountsResponse
+
+	operation := func() error {
+		var err error
+
+		resp, err = c.GetTransport().List(ctx, req)
+
+		return err
+	}
+
+	if err := c.retry(ctx, operation); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Walk lists accounts starting at the requested page and calls fn for every account,
+// following the next links until the pages run out, fn returns an error or ctx is done.
+func (c *AccountClient) Walk(ctx context.Context, req *utils.ListAccountsRequest, fn func(account *models.AccountData) error) error {
+	page := utils.ListAccountsRequest{}
+	if req != nil {
+		page = *req
+	}
+
+	for {
+		resp, err := c.ListWithContext(ctx, &page)
+		if err != nil {
+			return err
+		}
+
+		for i := range resp.Data {
+			if err := fn(&resp.Data[i]); err != nil {
+				return err
+			}
+		}
+
+		if !resp.HasNext() || len(resp.Data) == 0 {
+			return nil
+		}
+
+		next, err := utils.PageNumber(resp.Links.Next)
+		if err != nil {
+			return &errors.ErrPermanentFailure{Detail: "failed to parse next page link: " + resp.Links.Next}
+		}
+
+		if next <= page.PageNumber {
+			return nil
+		}
+
+		page.PageNumber = next
+	}
+}
+
+// retry runs operation with the retry policy of the client.
+func (c *AccountClient) retry(ctx context.Context, operation func() error) error {
+	return c.retryWithClassifier(ctx, operation, c.Classifier)
+}
+
+// retryWithClassifier retries like retry with classifier deciding which errors are retried.
+func (c *AccountClient) retryWithClassifier(ctx context.Context, operation func() error, classifier retry.Classifier) error {
+	opts := retry.Options{
+		Classifier:     classifier,
+		MaxRetryAfter:  c.MaxRetryAfter,
+		MaxElapsedTime: c.MaxElapsedTime,
+		Now:            c.Now,
+	}
+
+	return retry.RetryWithOptions(ctx, operation, c.Retry, opts, c.Logger)
+}
+
+// withTimeout bounds the context of an operation by the client Timeout.
+func (c *AccountClient) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.Timeout <= 0 {
+		return ctx, func() {}
 	}
 
-	return retry.Retry(operation, c.Retry, c.Logger)
+	return context.WithTimeout(ctx, c.Timeout)
 }
 func (c *AccountClient) GetTransport() transport.Transport {
 	return c.Transport
@@ -105,11 +500,11 @@ func (c *AccountClient) GetTransport() transport.Transport {
 func (c *AccountClient) SetTransport(t transport.Transport) {
 	c.Transport = t
 }
-func (c *AccountClient) GetRetry() retry.Retrier {
+func (c *AccountClient) GetRetry() retry.Policy {
 	return c.Retry
 }
-func (c *AccountClient) SetRetry(r retry.Retrier) {
-	c.Retry = r
+func (c *AccountClient) SetRetry(p retry.Policy) {
+	c.Retry = p
 }
 func (c *AccountClient) GetLogger() logging.LeveledLogger {
 	return c.Logger
@@ -2,18 +2,28 @@ package accounts_test
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	errors2 "errors"
+	"fmt"
+	http2 "net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/aabri-assignments/form3-accounts/v1/accounts"
+	errs "github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
 	mocks_retry "github.com/aabri-assignments/form3-accounts/v1/accounts/mocks"
 	"github.com/aabri-assignments/form3-accounts/v1/accounts/models"
 	"github.com/aabri-assignments/form3-accounts/v1/accounts/retry"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/transport"
 	"github.com/aabri-assignments/form3-accounts/v1/accounts/transport/http"
 	"github.com/aabri-assignments/form3-accounts/v1/accounts/utils"
 	"github.com/aabri-assignments/form3-accounts/v1/pkg/logging"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 type MockFailingTransport struct{}
@@ -30,6 +40,14 @@ func (m *MockFailingTransport) Fetch(ctx context.Context, accountID string) (*ut
 	return nil, errors.New("Failing operation")
 }
 
+func (m *MockFailingTransport) List(ctx context.Context, req *utils.ListAccountsRequest) (*utils.ListAccountsResponse, error) {
+	return nil, errors.New("Failing operation")
+}
+
+func (m *MockFailingTransport) Update(ctx context.Context, req *utils.UpdateAccountRequest) (*utils.UpdateAccountResponse, error) {
+	return nil, errors.New("Failing operation")
+}
+
 func TestNewClient(t *testing.T) {
 	opts := accounts.Options{
 		BaseURL:      "https://api.example.com",
@@ -59,6 +77,37 @@ func TestNewClient(t *testing.T) {
 	logger := client.GetLogger().(*logging.Leveled)
 	assert.Equal(t, opts.LogLevel, logger.Level, "LogLevel does not match")
 }
+func TestNewClientRetryPolicy(t *testing.T) {
+	t.Run("Selects a strategy by name", func(t *testing.T) {
+		client := accounts.New(accounts.Options{
+			Retries:       4,
+			InitialDelay:  100 * time.Millisecond,
+			MaxDelay:      time.Second,
+			RetryStrategy: retry.StrategyFibonacci,
+		})
+		assert.Equal(t, retry.NewFibonacciBackOff(100*time.Millisecond, time.Second, 4), client.GetRetry())
+	})
+	t.Run("Takes a policy by value", func(t *testing.T) {
+		policy := retry.NewEqualJitterBackOff(time.Millisecond, time.Second, 2)
+		client := accounts.New(accounts.Options{RetryPolicy: policy, RetryStrategy: retry.StrategyLinear})
+		assert.Same(t, policy, client.GetRetry())
+	})
+	t.Run("Caps the exponential backoff", func(t *testing.T) {
+		client := accounts.New(accounts.Options{InitialDelay: time.Second, MaxDelay: 10 * time.Millisecond})
+		backOff, ok := client.GetRetry().(*retry.ExponentialBackOff)
+		assert.True(t, ok)
+		assert.Equal(t, 10*time.Millisecond, backOff.MaxDelay)
+	})
+	t.Run("Rejects an unknown strategy", func(t *testing.T) {
+		opts := accounts.Options{Retries: 3, RetryStrategy: "quadratic", LogLevel: logging.LevelNull}
+		_, err := opts.NewRetryPolicy()
+		assert.Error(t, err)
+
+		_, ok := accounts.New(opts).GetRetry().(*retry.ExponentialBackOff)
+		assert.True(t, ok, "Expected New to fall back to the exponential backoff")
+	})
+}
+
 func TestClient(t *testing.T) {
 	mockTransport := &mocks_retry.MockTransport{}
 	mockRetries := &mocks_retry.MockRetrier{}
@@ -142,9 +191,71 @@ func TestClientFetch(t *testing.T) {
 	// Call the Fetch method that uses the retry mechanism
 	_, err := client.Fetch(accountID)
 
-	// Assert that the error is not nil and the error message is as expected
+	// Assert that the error is not nil and the error message is as expected, the retries may
+	// stop early with an ErrBudgetExhausted wrapping it when the jittered delays add up past Duration
 	assert.NotNil(t, err)
-	assert.Equal(t, "Failing operation", err.Error())
+	assert.ErrorContains(t, err, "Failing operation")
+}
+
+func TestClientRetryBudget(t *testing.T) {
+	now := time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+	mockTransport := &mocks_retry.MockTransport{}
+	mockTransport.On("Fetch", context.Background(), "some-id").Return((*utils.FetchAccountResponse)(nil), &errs.ErrNetwork{Err: errors.New("connection refused")}).Run(func(mock.Arguments) {
+		now = now.Add(time.Minute)
+	})
+
+	client := &accounts.AccountClient{
+		Transport:      mockTransport,
+		Retry:          retry.NewConstantBackOff(time.Millisecond, 10),
+		Logger:         &logging.Leveled{Level: logging.LevelError},
+		MaxElapsedTime: 3 * time.Minute,
+		Now:            func() time.Time { return now },
+	}
+
+	_, err := client.Fetch("some-id")
+
+	var exhausted *errs.ErrBudgetExhausted
+	assert.True(t, errors2.As(err, &exhausted))
+	assert.Equal(t, 3*time.Minute, exhausted.Budget)
+	assert.Equal(t, 3*time.Minute, exhausted.Elapsed)
+	assert.IsType(t, &errs.ErrNetwork{}, exhausted.Err)
+	mockTransport.AssertNumberOfCalls(t, "Fetch", 3)
+}
+
+func TestNewClientRetryBudget(t *testing.T) {
+	t.Run("Defaults the budget of every strategy to five minutes", func(t *testing.T) {
+		client := accounts.New(accounts.Options{RetryStrategy: retry.StrategyLinear}).(*accounts.AccountClient)
+		assert.Equal(t, retry.DefaultMaxElapsedTime, client.MaxElapsedTime)
+	})
+	t.Run("Keeps the budget of the policy", func(t *testing.T) {
+		policy := retry.NewExponentialBackOff(time.Minute, 3, time.Millisecond, 2, 0.1)
+		client := accounts.New(accounts.Options{RetryPolicy: policy}).(*accounts.AccountClient)
+		assert.Equal(t, time.Minute, client.MaxElapsedTime)
+	})
+	t.Run("Bounds a non-exponential strategy by Duration", func(t *testing.T) {
+		now := time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+		mockTransport := &mocks_retry.MockTransport{}
+		mockTransport.On("Fetch", context.Background(), "some-id").Return((*utils.FetchAccountResponse)(nil), &errs.ErrNetwork{Err: errors.New("connection refused")}).Run(func(mock.Arguments) {
+			now = now.Add(time.Minute)
+		})
+
+		client := accounts.New(accounts.Options{
+			Duration:      2 * time.Minute,
+			Retries:       10,
+			InitialDelay:  time.Millisecond,
+			RetryStrategy: retry.StrategyFibonacci,
+			LogLevel:      logging.LevelError,
+		}).(*accounts.AccountClient)
+		client.SetTransport(mockTransport)
+		client.Now = func() time.Time { return now }
+
+		_, err := client.Fetch("some-id")
+
+		var exhausted *errs.ErrBudgetExhausted
+		assert.True(t, errors2.As(err, &exhausted))
+		assert.Equal(t, 2*time.Minute, exhausted.Budget)
+		mockTransport.AssertNumberOfCalls(t, "Fetch", 2)
+	})
 }
 
 func TestClientSetRetry(t *testing.T) {
@@ -175,3 +286,486 @@ func TestClientSetLogger(t *testing.T) {
 	// Check if the Logger has been set correctly
 	assert.Equal(t, mockLogger, client.Logger, "SetLogger does not set the expected LeveledLogger")
 }
+
+func TestClientWithContext(t *testing.T) {
+	opts := accounts.Options{
+		BaseURL:      "https://api.example.com",
+		Retries:      3,
+		InitialDelay: time.Minute,
+		LogLevel:     logging.LevelError,
+	}
+	client := accounts.New(opts)
+	client.SetTransport(&MockFailingTransport{})
+
+	t.Run("FetchWithContext stops retrying when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := client.FetchWithContext(ctx, "test-account-id")
+		assert.True(t, errors2.Is(err, context.DeadlineExceeded))
+	})
+	t.Run("CreateWithContext passes the context to the transport", func(t *testing.T) {
+		mockTransport := &mocks_retry.MockTransport{}
+		client := &accounts.AccountClient{
+			Transport: mockTransport,
+			Retry:     &mocks_retry.MockRetrier{},
+			Logger:    &logging.Leveled{Level: logging.LevelError},
+		}
+		account := &models.AccountData{ID: "some-id"}
+		ctx := context.WithValue(context.Background(), struct{}{}, "value")
+
+		mockTransport.On("Create", ctx, &utils.CreateAccountRequest{Data: *account}).Return(&utils.CreateAccountResponse{Data: *account}, nil)
+		createdAccount, err := client.CreateWithContext(ctx, account)
+		assert.NoError(t, err)
+		assert.Equal(t, account, createdAccount)
+		mockTransport.AssertExpectations(t)
+	})
+	t.Run("DeleteWithContext returns the context error", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := client.DeleteWithContext(ctx, "test-account-id", 0)
+		assert.True(t, errors2.Is(err, context.Canceled))
+	})
+}
+
+func TestClientSigningFailure(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	// A retry would wait a minute and outlast the context, the signing failure must come back first.
+	client := accounts.New(accounts.Options{
+		BaseURL:      "http://localhost",
+		Retries:      3,
+		InitialDelay: time.Minute,
+		LogLevel:     logging.LevelError,
+		HTTP:         http.Options{Signer: &http.Signer{KeyID: "test-key-id", Key: key}},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.FetchWithContext(ctx, "some-id")
+	assert.IsType(t, &errs.ErrPermanentFailure{}, err)
+	assert.ErrorContains(t, err, "failed to sign request")
+}
+
+func TestClientFailOnRateLimit(t *testing.T) {
+	var calls int
+
+	server := httptest.NewServer(http2.HandlerFunc(func(w http2.ResponseWriter, r *http2.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		fmt.Fprint(w, `{"data": {"id": "some-id", "type": "accounts"}}`)
+	}))
+	defer server.Close()
+
+	// One request a minute: a retry would wait for the limiter and outlast the context.
+	client := accounts.New(accounts.Options{
+		BaseURL:      server.URL,
+		Retries:      3,
+		InitialDelay: time.Minute,
+		LogLevel:     logging.LevelError,
+		HTTP:         http.Options{RateLimiter: http.NewRateLimiter(1.0/60, 1), FailOnRateLimit: true},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := client.FetchWithContext(ctx, "some-id")
+	assert.NoError(t, err)
+
+	start := time.Now()
+	_, err = client.FetchWithContext(ctx, "some-id")
+
+	var rateLimited *errs.ErrRateLimited
+	assert.ErrorAs(t, err, &rateLimited)
+	assert.True(t, rateLimited.FailFast)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, 1, calls)
+}
+
+func TestClientWalk(t *testing.T) {
+	mockTransport := &mocks_retry.MockTransport{}
+	client := &accounts.AccountClient{
+		Transport: mockTransport,
+		Retry:     &mocks_retry.MockRetrier{},
+		Logger:    &logging.Leveled{Level: logging.LevelError},
+	}
+	ctx := context.Background()
+
+	mockTransport.On("List", ctx, &utils.ListAccountsRequest{PageNumber: 0, PageSize: 2}).Return(&utils.ListAccountsResponse{
+		Data: []models.AccountData{{ID: "1"}, {ID: "2"}},
+		Document: utils.Document{
+			Links: utils.Links{Next: "/v1/organisation/accounts?page%5Bnumber%5D=1&page%5Bsize%5D=2"},
+		},
+	}, nil)
+	mockTransport.On("List", ctx, &utils.ListAccountsRequest{PageNumber: 1, PageSize: 2}).Return(&utils.ListAccountsResponse{
+		Data: []models.AccountData{{ID: "3"}},
+	}, nil)
+
+	t.Run("Follows next links until the pages run out", func(t *testing.T) {
+		var ids []string
+		err := client.Walk(ctx, &utils.ListAccountsRequest{PageSize: 2}, func(account *models.AccountData) error {
+			ids = append(ids, account.ID)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"1", "2", "3"}, ids)
+		mockTransport.AssertExpectations(t)
+	})
+	t.Run("Stops when the callback returns an error", func(t *testing.T) {
+		stop := errors.New("stop")
+		calls := 0
+		err := client.Walk(ctx, &utils.ListAccountsRequest{PageSize: 2}, func(account *models.AccountData) error {
+			calls++
+			return stop
+		})
+		assert.Equal(t, stop, err)
+		assert.Equal(t, 1, calls)
+	})
+	t.Run("Stops when the context is cancelled", func(t *testing.T) {
+		cancelCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		err := client.Walk(cancelCtx, &utils.ListAccountsRequest{PageSize: 2}, func(account *models.AccountData) error {
+			return nil
+		})
+		assert.True(t, errors2.Is(err, context.Canceled))
+	})
+	t.Run("Rejects an invalid filter before sending it", func(t *testing.T) {
+		req := &utils.ListAccountsRequest{PageSize: 2, Filter: accounts.Filter{Country: "gb"}}
+
+		_, err := client.ListWithContext(ctx, req)
+		assert.IsType(t, &errs.ErrBadRequest{}, err)
+
+		err = client.Walk(ctx, req, func(account *models.AccountData) error {
+			return nil
+		})
+		assert.IsType(t, &errs.ErrBadRequest{}, err)
+		mockTransport.AssertNotCalled(t, "List", ctx, req)
+	})
+}
+
+func TestClientUpdate(t *testing.T) {
+	mockTransport := &mocks_retry.MockTransport{}
+	client := &accounts.AccountClient{
+		Transport: mockTransport,
+		Retry:     retry.NewExponentialBackOff(time.Minute, 3, time.Millisecond, 2, 0.1),
+		Logger:    &logging.Leveled{Level: logging.LevelError},
+	}
+	status := "closed"
+	attributes := &models.AccountAttributesUpdate{Name: []string{"Jane Smith"}, Status: &status}
+	version := int64(1)
+
+	t.Run("Sends the version and the changed attributes", func(t *testing.T) {
+		req := &utils.UpdateAccountRequest{Data: utils.UpdateAccountData{ID: "some-id", Type: "accounts", Version: 0, Attributes: *attributes}}
+		updated := models.AccountData{ID: "some-id", Version: &version}
+		mockTransport.On("Update", context.Background(), req).Return(&utils.UpdateAccountResponse{Data: updated}, nil).Once()
+
+		account, err := client.Update("some-id", 0, attributes)
+		assert.NoError(t, err)
+		assert.Equal(t, &updated, account)
+		mockTransport.AssertExpectations(t)
+	})
+	t.Run("Returns a conflict without retrying", func(t *testing.T) {
+		req := &utils.UpdateAccountRequest{Data: utils.UpdateAccountData{ID: "other-id", Type: "accounts", Version: 5, Attributes: *attributes}}
+		mockTransport.On("Update", context.Background(), req).Return((*utils.UpdateAccountResponse)(nil), &errs.ErrConflict{Detail: "invalid version"}).Once()
+
+		_, err := client.Update("other-id", 5, attributes)
+		var conflictErr *errs.ErrConflict
+		assert.True(t, errors2.As(err, &conflictErr))
+		mockTransport.AssertNumberOfCalls(t, "Update", 2)
+	})
+}
+
+func TestClientDeleteLatest(t *testing.T) {
+	ctx := context.Background()
+	newClient := func(mockTransport *mocks_retry.MockTransport) *accounts.AccountClient {
+		return &accounts.AccountClient{
+			Transport: mockTransport,
+			Retry:     retry.NewConstantBackOff(time.Millisecond, 5),
+			Logger:    &logging.Leveled{Level: logging.LevelError},
+		}
+	}
+	fetchResponse := func(version int64) *utils.FetchAccountResponse {
+		return &utils.FetchAccountResponse{Data: models.AccountData{ID: "some-id", Version: &version}}
+	}
+
+	t.Run("Deletes the current version", func(t *testing.T) {
+		mockTransport := &mocks_retry.MockTransport{}
+		mockTransport.On("Fetch", ctx, "some-id").Return(fetchResponse(2), nil).Once()
+		mockTransport.On("Delete", ctx, &utils.DeleteAccountRequest{ID: "some-id", Version: 2}).Return(nil).Once()
+
+		err := newClient(mockTransport).DeleteLatest(ctx, "some-id", accounts.DeleteLatestOptions{})
+		assert.NoError(t, err)
+		mockTransport.AssertExpectations(t)
+	})
+	t.Run("Re-fetches the version after a conflict", func(t *testing.T) {
+		mockTransport := &mocks_retry.MockTransport{}
+		mockTransport.On("Fetch", ctx, "some-id").Return(fetchResponse(0), nil).Once()
+		mockTransport.On("Delete", ctx, &utils.DeleteAccountRequest{ID: "some-id", Version: 0}).Return(&errs.ErrConflict{Detail: "invalid version"}).Once()
+		mockTransport.On("Fetch", ctx, "some-id").Return(fetchResponse(1), nil).Once()
+		mockTransport.On("Delete", ctx, &utils.DeleteAccountRequest{ID: "some-id", Version: 1}).Return(nil).Once()
+
+		err := newClient(mockTransport).DeleteLatest(ctx, "some-id", accounts.DeleteLatestOptions{})
+		assert.NoError(t, err)
+		mockTransport.AssertExpectations(t)
+	})
+	t.Run("Gives up after MaxConflictRetries", func(t *testing.T) {
+		mockTransport := &mocks_retry.MockTransport{}
+		mockTransport.On("Fetch", ctx, "some-id").Return(fetchResponse(0), nil)
+		mockTransport.On("Delete", ctx, &utils.DeleteAccountRequest{ID: "some-id", Version: 0}).Return(&errs.ErrConflict{Detail: "invalid version"})
+
+		err := newClient(mockTransport).DeleteLatest(ctx, "some-id", accounts.DeleteLatestOptions{MaxConflictRetries: 2})
+		assert.IsType(t, &errs.ErrConflict{}, err)
+		mockTransport.AssertNumberOfCalls(t, "Delete", 3)
+	})
+	t.Run("Treats a missing account as deleted when asked to", func(t *testing.T) {
+		mockTransport := &mocks_retry.MockTransport{}
+		mockTransport.On("Fetch", ctx, "some-id").Return((*utils.FetchAccountResponse)(nil), &errs.ErrNotFound{ResourceID: "some-id"})
+
+		client := newClient(mockTransport)
+
+		err := client.DeleteLatest(ctx, "some-id", accounts.DeleteLatestOptions{IgnoreNotFound: true})
+		assert.NoError(t, err)
+		mockTransport.AssertNumberOfCalls(t, "Fetch", 1)
+
+		err = client.DeleteLatest(ctx, "some-id", accounts.DeleteLatestOptions{})
+		assert.IsType(t, &errs.ErrNotFound{}, err)
+		mockTransport.AssertNumberOfCalls(t, "Fetch", 2)
+	})
+	t.Run("Counts conflicts against MaxElapsedTime", func(t *testing.T) {
+		now := time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+		mockTransport := &mocks_retry.MockTransport{}
+		mockTransport.On("Fetch", ctx, "some-id").Return(fetchResponse(0), nil)
+		mockTransport.On("Delete", ctx, &utils.DeleteAccountRequest{ID: "some-id", Version: 0}).Return(&errs.ErrConflict{Detail: "invalid version"}).Run(func(mock.Arguments) {
+			now = now.Add(time.Minute)
+		})
+
+		client := newClient(mockTransport)
+		client.MaxElapsedTime = 3 * time.Minute
+		client.Now = func() time.Time { return now }
+
+		err := client.DeleteLatest(ctx, "some-id", accounts.DeleteLatestOptions{MaxConflictRetries: 10})
+
+		var exhausted *errs.ErrBudgetExhausted
+		assert.True(t, errors2.As(err, &exhausted))
+		assert.Equal(t, 3*time.Minute, exhausted.Elapsed)
+		assert.IsType(t, &errs.ErrConflict{}, exhausted.Err)
+		mockTransport.AssertNumberOfCalls(t, "Delete", 3)
+	})
+}
+
+func TestClientClassifier(t *testing.T) {
+	ctx := context.Background()
+	newClient := func(mockTransport *mocks_retry.MockTransport) *accounts.AccountClient {
+		return &accounts.AccountClient{
+			Transport: mockTransport,
+			Retry:     retry.NewConstantBackOff(time.Millisecond, 3),
+			Logger:    &logging.Leveled{Level: logging.LevelError},
+		}
+	}
+
+	t.Run("Does not retry a missing account", func(t *testing.T) {
+		mockTransport := &mocks_retry.MockTransport{}
+		mockTransport.On("Fetch", ctx, "some-id").Return((*utils.FetchAccountResponse)(nil), &errs.ErrNotFound{ResourceID: "some-id"})
+
+		_, err := newClient(mockTransport).Fetch("some-id")
+		assert.IsType(t, &errs.ErrNotFound{}, err)
+		mockTransport.AssertNumberOfCalls(t, "Fetch", 1)
+	})
+	t.Run("Retries server errors", func(t *testing.T) {
+		mockTransport := &mocks_retry.MockTransport{}
+		mockTransport.On("Fetch", ctx, "some-id").Return((*utils.FetchAccountResponse)(nil), &errs.APIError{StatusCode: 503, Message: "Service Unavailable"}).Twice()
+		mockTransport.On("Fetch", ctx, "some-id").Return(&utils.FetchAccountResponse{Data: models.AccountData{ID: "some-id"}}, nil).Once()
+
+		account, err := newClient(mockTransport).Fetch("some-id")
+		assert.NoError(t, err)
+		assert.Equal(t, "some-id", account.ID)
+		mockTransport.AssertNumberOfCalls(t, "Fetch", 3)
+	})
+	t.Run("Uses the given classifier", func(t *testing.T) {
+		mockTransport := &mocks_retry.MockTransport{}
+		mockTransport.On("Fetch", ctx, "some-id").Return((*utils.FetchAccountResponse)(nil), &errs.ErrNotFound{ResourceID: "some-id"})
+
+		client := newClient(mockTransport)
+		client.Classifier = retry.ClassifierFunc(func(err error) bool { return true })
+
+		_, err := client.Fetch("some-id")
+		assert.IsType(t, &errs.ErrNotFound{}, err)
+		mockTransport.AssertNumberOfCalls(t, "Fetch", 4)
+	})
+}
+
+// flakyTransport fails the first fetches of an account with a network error, safe for concurrent use.
+type flakyTransport struct {
+	transport.Transport
+	failures map[string]int
+
+	mu      sync.Mutex
+	fetches map[string]int
+}
+
+func (f *flakyTransport) Fetch(ctx context.Context, accountID string) (*utils.FetchAccountResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.fetches[accountID]++
+	if f.fetches[accountID] <= f.failures[accountID] {
+		return nil, &errs.ErrNetwork{Err: errors.New("connection refused")}
+	}
+
+	return &utils.FetchAccountResponse{Data: models.AccountData{ID: accountID}}, nil
+}
+
+func TestClientRetryState(t *testing.T) {
+	newClient := func(failures map[string]int) accounts.Client {
+		client := accounts.New(accounts.Options{Retries: 2, InitialDelay: time.Millisecond, LogLevel: logging.LevelError})
+		client.SetTransport(&flakyTransport{failures: failures, fetches: make(map[string]int)})
+
+		return client
+	}
+
+	t.Run("Later calls get their retries after a call ran out of them", func(t *testing.T) {
+		client := newClient(map[string]int{"down": 10, "flaky-1": 2, "flaky-2": 2})
+
+		_, err := client.Fetch("down")
+		assert.IsType(t, &errs.ErrNetwork{}, err)
+
+		for _, id := range []string{"flaky-1", "flaky-2"} {
+			account, err := client.Fetch(id)
+			assert.NoError(t, err)
+			assert.Equal(t, id, account.ID)
+		}
+	})
+	t.Run("Concurrent calls share the client", func(t *testing.T) {
+		failures := make(map[string]int)
+		for i := 0; i < 50; i++ {
+			failures[fmt.Sprintf("account-%d", i)] = 2
+		}
+
+		client := newClient(failures)
+
+		var wg sync.WaitGroup
+
+		for id := range failures {
+			wg.Add(1)
+
+			go func(id string) {
+				defer wg.Done()
+
+				account, err := client.FetchWithContext(context.Background(), id)
+				if assert.NoError(t, err) {
+					assert.Equal(t, id, account.ID)
+				}
+			}(id)
+		}
+
+		wg.Wait()
+	})
+}
+
+func TestClientWithDocument(t *testing.T) {
+	mockTransport := &mocks_retry.MockTransport{}
+	client := &accounts.AccountClient{
+		Transport: mockTransport,
+		Retry:     &mocks_retry.MockRetrier{},
+		Logger:    &logging.Leveled{Level: logging.LevelError},
+	}
+	ctx := context.Background()
+	document := utils.Document{
+		Links: utils.Links{Self: "/v1/organisation/accounts/some-id"},
+		Meta:  map[string]interface{}{"request_id": "abc"},
+	}
+	account := models.AccountData{ID: "some-id"}
+
+	t.Run("FetchWithDocument returns the links and meta", func(t *testing.T) {
+		mockTransport.On("Fetch", ctx, "some-id").Return(&utils.FetchAccountResponse{Document: document, Data: account}, nil)
+
+		fetched, doc, err := client.FetchWithDocument(ctx, "some-id")
+		assert.NoError(t, err)
+		assert.Equal(t, &account, fetched)
+		assert.Equal(t, &document, doc)
+	})
+	t.Run("CreateWithDocument returns the links and meta", func(t *testing.T) {
+		mockTransport.On("Create", ctx, &utils.CreateAccountRequest{Data: account}).Return(&utils.CreateAccountResponse{Document: document, Data: account}, nil)
+
+		created, doc, err := client.CreateWithDocument(ctx, &account)
+		assert.NoError(t, err)
+		assert.Equal(t, &account, created)
+		assert.Equal(t, &document, doc)
+	})
+}
+
+func TestClientCreateValidation(t *testing.T) {
+	mockTransport := &mocks_retry.MockTransport{}
+	client := accounts.New(accounts.Options{BaseURL: "https://api.example.com"})
+	client.SetTransport(mockTransport)
+
+	t.Run("Invalid accounts are not sent", func(t *testing.T) {
+		_, err := client.Create(&models.AccountData{ID: "some-id"})
+
+		var validationErr *errs.ErrValidation
+		assert.True(t, errors2.As(err, &validationErr))
+		assert.NotEmpty(t, validationErr.Fields)
+		mockTransport.AssertNotCalled(t, "Create")
+	})
+	t.Run("Validation can be disabled", func(t *testing.T) {
+		client := accounts.New(accounts.Options{BaseURL: "https://api.example.com", DisableValidation: true})
+		client.SetTransport(mockTransport)
+		account := &models.AccountData{ID: "some-id"}
+
+		mockTransport.On("Create", context.Background(), &utils.CreateAccountRequest{Data: *account}).Return(&utils.CreateAccountResponse{Data: *account}, nil)
+		_, err := client.Create(account)
+		assert.NoError(t, err)
+		mockTransport.AssertExpectations(t)
+	})
+}
+
+func TestClientCreateGeneratesIBAN(t *testing.T) {
+	mockTransport := &mocks_retry.MockTransport{}
+	client := accounts.New(accounts.Options{BaseURL: "https://api.example.com", GenerateIBAN: true})
+	client.SetTransport(mockTransport)
+
+	country := "GB"
+	account := &models.AccountData{
+		ID:             "some-id",
+		OrganisationID: "some-org-id",
+		Attributes: &models.AccountAttributes{
+			Country:       &country,
+			BankID:        "601613",
+			BankIDCode:    "GBDSC",
+			Bic:           "NWBKGB22",
+			AccountNumber: "31926819",
+			Name:          []string{"Samantha Holder"},
+		},
+	}
+	expected := *account
+	expectedAttributes := *account.Attributes
+	expectedAttributes.Iban = "GB29NWBK60161331926819"
+	expected.Attributes = &expectedAttributes
+
+	mockTransport.On("Create", context.Background(), &utils.CreateAccountRequest{Data: expected}).Return(&utils.CreateAccountResponse{Data: expected}, nil)
+	created, err := client.Create(account)
+	assert.NoError(t, err)
+	assert.Equal(t, "GB29NWBK60161331926819", created.Attributes.Iban)
+	assert.Empty(t, account.Attributes.Iban, "Create should not modify the caller's account")
+	mockTransport.AssertExpectations(t)
+}
+
+func TestClientTimeout(t *testing.T) {
+	client := accounts.New(accounts.Options{
+		BaseURL:      "https://api.example.com",
+		Retries:      3,
+		InitialDelay: time.Minute,
+		LogLevel:     logging.LevelError,
+		Timeout:      20 * time.Millisecond,
+	})
+	client.SetTransport(&MockFailingTransport{})
+
+	start := time.Now()
+	_, err := client.Fetch("test-account-id")
+	assert.True(t, errors2.Is(err, context.DeadlineExceeded))
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -1,6 +1,10 @@
 package errors
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // ErrBadRequest represents a 400 Bad Request error.
 type ErrBadRequest struct {
@@ -20,11 +24,93 @@ func (e *ErrNotFound) Error() string {
 	return fmt.Sprintf("resource not found with ID: %s", e.ResourceID)
 }
 
+// ErrConflict represents a 409 Conflict error, returned when the version sent does not match the resource.
+type ErrConflict struct {
+	Detail string
+}
+
+func (e *ErrConflict) Error() string {
+	return fmt.Sprintf("conflict: %s", e.Detail)
+}
+
+// ErrRateLimited represents a request held back by the client-side rate limiter.
+// Wait is how long until the limiter lets a request through. FailFast is set when the request was not
+// allowed to wait, because the client fails on rate limiting or the wait would outlast its deadline,
+// so it is not retried either.
+type ErrRateLimited struct {
+	Wait     time.Duration
+	FailFast bool
+}
+
+func (e *ErrRateLimited) Error() string {
+	return fmt.Sprintf("rate limited: retry in %s", e.Wait)
+}
+
+// ErrNetwork represents a request that failed before an answer of the API was received, such as a refused
+// connection or a timeout. Err is the error of the HTTP client.
+type ErrNetwork struct {
+	Err error
+}
+
+func (e *ErrNetwork) Error() string {
+	return fmt.Sprintf("network error: %v", e.Err)
+}
+
+func (e *ErrNetwork) Unwrap() error {
+	return e.Err
+}
+
+// ErrBudgetExhausted represents an operation that was not retried again because the next attempt would start
+// after its time budget. Elapsed is the time spent since the first attempt and Err is the error of the last one.
+type ErrBudgetExhausted struct {
+	Budget  time.Duration
+	Elapsed time.Duration
+	Err     error
+}
+
+func (e *ErrBudgetExhausted) Error() string {
+	return fmt.Sprintf("retry budget of %s exhausted after %s: %v", e.Budget, e.Elapsed, e.Err)
+}
+
+func (e *ErrBudgetExhausted) Unwrap() error {
+	return e.Err
+}
+
 // ErrPermanentFailure represents a permanent failure that should not be retried.
 type ErrPermanentFailure struct {
 	Detail string
+	// Err is the underlying error when there is one, it is not part of the message.
+	Err error
 }
 
 func (e *ErrPermanentFailure) Error() string {
 	return fmt.Sprintf("permanent failure: %s", e.Detail)
 }
+
+func (e *ErrPermanentFailure) Unwrap() error {
+	return e.Err
+}
+
+// FieldError describes why a single account field is invalid.
+type FieldError struct {
+	Field  string
+	Reason string
+}
+
+func (e FieldError) String() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Reason)
+}
+
+// ErrValidation represents an account that failed client-side validation before being sent.
+type ErrValidation struct {
+	Fields []FieldError
+}
+
+func (e *ErrValidation) Error() string {
+	reasons := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		reasons = append(reasons, field.String())
+	}
+
+	return fmt.Sprintf("validation failed: %s", strings.Join(reasons, "; "))
+}
@@ -1,8 +1,10 @@
 package errors_test
 
 import (
+	errs "errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
 	"github.com/stretchr/testify/assert"
@@ -25,11 +27,39 @@ func TestErrors(t *testing.T) {
 		assert.Equal(t, expectedMessage, err.Error())
 	})
 
+	t.Run("ErrConflict", func(t *testing.T) {
+		detail := "invalid version"
+		err := &errors.ErrConflict{Detail: detail}
+		expectedMessage := fmt.Sprintf("conflict: %s", detail)
+
+		assert.Equal(t, expectedMessage, err.Error())
+	})
+
+	t.Run("ErrValidation", func(t *testing.T) {
+		err := &errors.ErrValidation{Fields: []errors.FieldError{
+			{Field: "bank_id", Reason: "is required"},
+			{Field: "iban", Reason: "is not supported"},
+		}}
+		expectedMessage := "validation failed: bank_id: is required; iban: is not supported"
+
+		assert.Equal(t, expectedMessage, err.Error())
+	})
+
+	t.Run("ErrRateLimited", func(t *testing.T) {
+		err := &errors.ErrRateLimited{Wait: 1500 * time.Millisecond}
+		expectedMessage := "rate limited: retry in 1.5s"
+
+		assert.Equal(t, expectedMessage, err.Error())
+	})
+
 	t.Run("ErrPermanentFailure", func(t *testing.T) {
 		detail := "database connection error"
 		err := &errors.ErrPermanentFailure{Detail: detail}
 		expectedMessage := fmt.Sprintf("permanent failure: %s", detail)
 
 		assert.Equal(t, expectedMessage, err.Error())
+
+		cause := errs.New("connection refused")
+		assert.ErrorIs(t, &errors.ErrPermanentFailure{Detail: detail, Err: cause}, cause)
 	})
 }
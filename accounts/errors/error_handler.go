@@ -3,20 +3,33 @@ package errors
 import (
 	"encoding/json"
 	"io"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // APIError represents a general API error with a status code and message.
 type APIError struct {
 	StatusCode int
 	Message    string
+	// RetryAfter is the delay the API asked for with a Retry-After header, zero when it did not.
+	RetryAfter time.Duration
 }
 
 func (e *APIError) Error() string {
 	return e.Message
 }
 
+// apiErrorBody is the body of an error answer, the API sends error_message and some proxies message.
+type apiErrorBody struct {
+	ErrorMessage string `json:"error_message"`
+	Message      string `json:"message"`
+}
+
 // HandleHTTPError checks the HTTP response for errors and returns the appropriate custom error type.
+// When the body of an error answer is not JSON, the status text is used as the message.
 func HandleHTTPError(resp *http.Response) error {
 	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
 		return nil
@@ -28,22 +41,60 @@ func HandleHTTPError(resp *http.Response) error {
 		return &ErrPermanentFailure{Detail: "failed to read response body"}
 	}
 
-	var apiErr APIError
-
-	err = json.Unmarshal(bodyBytes, &apiErr)
+	message := http.StatusText(resp.StatusCode)
 
-	if err != nil {
-		return &ErrPermanentFailure{Detail: "failed to unmarshal API error"}
+	var body apiErrorBody
+	if err := json.Unmarshal(bodyBytes, &body); err == nil {
+		switch {
+		case body.ErrorMessage != "":
+			message = body.ErrorMessage
+		case body.Message != "":
+			message = body.Message
+		}
 	}
 
-	apiErr.StatusCode = resp.StatusCode
-
 	switch resp.StatusCode {
 	case http.StatusBadRequest:
-		return &ErrBadRequest{Detail: apiErr.Message}
+		return &ErrBadRequest{Detail: message}
 	case http.StatusNotFound:
-		return &ErrNotFound{ResourceID: apiErr.Message}
+		return &ErrNotFound{ResourceID: message}
+	case http.StatusConflict:
+		return &ErrConflict{Detail: message}
 	default:
-		return &apiErr
+		retryAfter, _ := ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+
+		return &APIError{StatusCode: resp.StatusCode, Message: message, RetryAfter: retryAfter}
 	}
 }
+
+// ParseRetryAfter parses the value of a Retry-After header, either a number of seconds or an HTTP date,
+// into the delay it asks for from now. A date in the past asks for no delay.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		if seconds > int64(math.MaxInt64/time.Second) {
+			return time.Duration(math.MaxInt64), true
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	if delay := date.Sub(now); delay > 0 {
+		return delay, true
+	}
+
+	return 0, true
+}
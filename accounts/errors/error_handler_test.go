@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
 	errs "github.com/pkg/errors"
@@ -46,6 +47,14 @@ func TestHandleHTTPError(t *testing.T) {
 			},
 			expectedResult: &errors.ErrNotFound{ResourceID: "123456"},
 		},
+		{
+			name: "conflict",
+			response: &http.Response{
+				StatusCode: http.StatusConflict,
+				Body:       io.NopCloser(bytes.NewReader([]byte(`{"message": "invalid version"}`))),
+			},
+			expectedResult: &errors.ErrConflict{Detail: "invalid version"},
+		},
 		{
 			name: "other_error",
 			response: &http.Response{
@@ -54,6 +63,64 @@ func TestHandleHTTPError(t *testing.T) {
 			},
 			expectedResult: &errors.APIError{StatusCode: http.StatusInternalServerError, Message: "internal server error"},
 		},
+		{
+			name: "error_message",
+			response: &http.Response{
+				StatusCode: http.StatusNotFound,
+				Body:       io.NopCloser(bytes.NewReader([]byte(`{"error_message": "record 123456 does not exist"}`))),
+			},
+			expectedResult: &errors.ErrNotFound{ResourceID: "record 123456 does not exist"},
+		},
+		{
+			name: "too_many_requests",
+			response: &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Body:       io.NopCloser(bytes.NewReader([]byte(`{"error_message": "slow down"}`))),
+			},
+			expectedResult: &errors.APIError{StatusCode: http.StatusTooManyRequests, Message: "slow down"},
+		},
+		{
+			name: "unauthorized",
+			response: &http.Response{
+				StatusCode: http.StatusUnauthorized,
+				Body:       io.NopCloser(bytes.NewReader([]byte(`{"error_message": "invalid token"}`))),
+			},
+			expectedResult: &errors.APIError{StatusCode: http.StatusUnauthorized, Message: "invalid token"},
+		},
+		{
+			name: "non_json_body",
+			response: &http.Response{
+				StatusCode: http.StatusBadGateway,
+				Body:       io.NopCloser(bytes.NewReader([]byte(`<html>Bad Gateway</html>`))),
+			},
+			expectedResult: &errors.APIError{StatusCode: http.StatusBadGateway, Message: "Bad Gateway"},
+		},
+		{
+			name: "retry_after_seconds",
+			response: &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{"Retry-After": {"3"}},
+				Body:       io.NopCloser(bytes.NewReader([]byte(`{"error_message": "slow down"}`))),
+			},
+			expectedResult: &errors.APIError{StatusCode: http.StatusTooManyRequests, Message: "slow down", RetryAfter: 3 * time.Second},
+		},
+		{
+			name: "invalid_retry_after",
+			response: &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Header:     http.Header{"Retry-After": {"soon"}},
+				Body:       http.NoBody,
+			},
+			expectedResult: &errors.APIError{StatusCode: http.StatusServiceUnavailable, Message: "Service Unavailable"},
+		},
+		{
+			name: "empty_body",
+			response: &http.Response{
+				StatusCode: http.StatusNotFound,
+				Body:       http.NoBody,
+			},
+			expectedResult: &errors.ErrNotFound{ResourceID: "Not Found"},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -93,8 +160,52 @@ func TestHandleHTTPError_FailedToUnmarshalAPIError(t *testing.T) {
 		Body:       io.NopCloser(bytes.NewReader([]byte(invalidJSON))),
 	}
 
+	// The status decides the error, so a 5xx answer is retried whatever its body.
 	err := errors.HandleHTTPError(response)
 	assert.Error(t, err)
-	assert.IsType(t, &errors.ErrPermanentFailure{}, err)
-	assert.Equal(t, "permanent failure: failed to unmarshal API error", err.Error())
+	assert.Equal(t, &errors.APIError{StatusCode: http.StatusInternalServerError, Message: "Internal Server Error"}, err)
+}
+
+func TestHandleHTTPError_RetryAfterDate(t *testing.T) {
+	response := &http.Response{
+		StatusCode: http.StatusServiceUnavailable,
+		Header:     http.Header{"Retry-After": {time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)}},
+		Body:       http.NoBody,
+	}
+
+	err := errors.HandleHTTPError(response)
+
+	var apiErr *errors.APIError
+	assert.True(t, errs.As(err, &apiErr))
+	assert.InDelta(t, time.Hour, apiErr.RetryAfter, float64(2*time.Second))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{name: "seconds", value: "120", expected: 2 * time.Minute, ok: true},
+		{name: "zero_seconds", value: "0", expected: 0, ok: true},
+		{name: "padded_seconds", value: " 5 ", expected: 5 * time.Second, ok: true},
+		{name: "http_date", value: "Wed, 01 Mar 2023 12:00:30 GMT", expected: 30 * time.Second, ok: true},
+		{name: "rfc850_date", value: "Wednesday, 01-Mar-23 12:01:00 GMT", expected: time.Minute, ok: true},
+		{name: "past_date", value: "Wed, 01 Mar 2023 11:00:00 GMT", expected: 0, ok: true},
+		{name: "huge_seconds", value: "99999999999999", expected: time.Duration(1<<63 - 1), ok: true},
+		{name: "negative_seconds", value: "-1", ok: false},
+		{name: "empty", value: "", ok: false},
+		{name: "invalid", value: "in a while", ok: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			delay, ok := errors.ParseRetryAfter(tc.value, now)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, delay)
+		})
+	}
 }
@@ -0,0 +1,7 @@
+package accounts
+
+import "github.com/aabri-assignments/form3-accounts/v1/accounts/utils"
+
+// Filter is a typed set of server-side filters applied when listing accounts, it is validated before
+// the list request is sent. Empty fields are not sent.
+type Filter = utils.AccountFilter
@@ -0,0 +1,60 @@
+package accounts_test
+
+import (
+	"testing"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter(t *testing.T) {
+	t.Run("Params only contains non-empty filters", func(t *testing.T) {
+		filter := accounts.Filter{BankID: "400300", Country: "GB"}
+		assert.Equal(t, map[string]string{"bank_id": "400300", "country": "GB"}, filter.Params())
+	})
+	t.Run("Set only accepts the attributes the API filters on", func(t *testing.T) {
+		var filter accounts.Filter
+		assert.True(t, filter.Set("bank_id_code", "GBDSC"))
+		assert.False(t, filter.Set("colour", "red"))
+		assert.Equal(t, accounts.Filter{BankIDCode: "GBDSC"}, filter)
+	})
+	t.Run("Apply sets the filter on the request", func(t *testing.T) {
+		req := &utils.ListAccountsRequest{PageSize: 10}
+		filter := accounts.Filter{
+			BankID:        "400300",
+			BankIDCode:    "GBDSC",
+			AccountNumber: "41426819",
+			Iban:          "GB11NWBK40030041426819",
+			Country:       "GB",
+			CustomerID:    "customer-1",
+		}
+		err := filter.Apply(req)
+		assert.NoError(t, err)
+		assert.Equal(t, filter, req.Filter)
+		assert.Len(t, req.Filter.Params(), 6)
+		assert.Equal(t, 10, req.PageSize)
+	})
+
+	testCases := []struct {
+		name   string
+		filter accounts.Filter
+	}{
+		{name: "invalid bank_id", filter: accounts.Filter{BankID: "400 300"}},
+		{name: "invalid bank_id_code", filter: accounts.Filter{BankIDCode: "gbdsc"}},
+		{name: "invalid account_number", filter: accounts.Filter{AccountNumber: "invalid!"}},
+		{name: "invalid iban", filter: accounts.Filter{Iban: "invalid"}},
+		{name: "invalid country", filter: accounts.Filter{Country: "GBR"}},
+		{name: "invalid customer_id", filter: accounts.Filter{CustomerID: "customer/1"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := &utils.ListAccountsRequest{}
+			err := tc.filter.Apply(req)
+			assert.IsType(t, &errors.ErrBadRequest{}, err)
+			assert.Equal(t, accounts.Filter{}, req.Filter)
+		})
+	}
+}
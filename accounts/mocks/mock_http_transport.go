@@ -30,3 +30,15 @@ func (m *MockTransport) Delete(ctx context.Context, req *utils.DeleteAccountRequ
 
 	return args.Error(0)
 }
+
+func (m *MockTransport) List(ctx context.Context, req *utils.ListAccountsRequest) (*utils.ListAccountsResponse, error) {
+	args := m.Called(ctx, req)
+
+	return args.Get(0).(*utils.ListAccountsResponse), args.Error(1)
+}
+
+func (m *MockTransport) Update(ctx context.Context, req *utils.UpdateAccountRequest) (*utils.UpdateAccountResponse, error) {
+	args := m.Called(ctx, req)
+
+	return args.Get(0).(*utils.UpdateAccountResponse), args.Error(1)
+}
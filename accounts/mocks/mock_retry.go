@@ -1,9 +1,17 @@
 package mocks
 
-import "time"
+import (
+	"time"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/retry"
+)
 
 type MockRetrier struct{}
 
+func (m *MockRetrier) NewRetrier() retry.Retrier {
+	return m
+}
+
 func (m *MockRetrier) Attempt() int {
 	return 1
 }
@@ -1,27 +1,79 @@
 package models
 
+import "time"
+
 type AccountData struct {
-	Attributes     *AccountAttributes `json:"attributes,omitempty"`
-	ID             string             `json:"id,omitempty"`
-	OrganisationID string             `json:"organisation_id,omitempty"`
-	Type           string             `json:"type,omitempty"`
-	Version        *int64             `json:"version,omitempty"`
+	Attributes     *AccountAttributes      `json:"attributes,omitempty"`
+	CreatedOn      *time.Time              `json:"created_on,omitempty"`
+	ID             string                  `json:"id,omitempty"`
+	Links          *ResourceLinks          `json:"links,omitempty"`
+	ModifiedOn     *time.Time              `json:"modified_on,omitempty"`
+	OrganisationID string                  `json:"organisation_id,omitempty"`
+	Relationships  map[string]Relationship `json:"relationships,omitempty"`
+	Type           string                  `json:"type,omitempty"`
+	Version        *int64                  `json:"version,omitempty"`
 }
 
 type AccountAttributes struct {
-	AccountClassification   *string  `json:"account_classification,omitempty"`
-	AccountMatchingOptOut   *bool    `json:"account_matching_opt_out,omitempty"`
-	AccountNumber           string   `json:"account_number,omitempty"`
-	AlternativeNames        []string `json:"alternative_names,omitempty"`
-	BankID                  string   `json:"bank_id,omitempty"`
-	BankIDCode              string   `json:"bank_id_code,omitempty"`
-	BaseCurrency            string   `json:"base_currency,omitempty"`
-	Bic                     string   `json:"bic,omitempty"`
-	Country                 *string  `json:"country,omitempty"`
-	Iban                    string   `json:"iban,omitempty"`
-	JointAccount            *bool    `json:"joint_account,omitempty"`
-	Name                    []string `json:"name,omitempty"`
-	SecondaryIdentification string   `json:"secondary_identification,omitempty"`
-	Status                  *string  `json:"status,omitempty"`
-	Switched                *bool    `json:"switched,omitempty"`
+	AcceptanceQualifier        string                      `json:"acceptance_qualifier,omitempty"`
+	AccountClassification      *string                     `json:"account_classification,omitempty"`
+	AccountMatchingOptOut      *bool                       `json:"account_matching_opt_out,omitempty"`
+	AccountNumber              string                      `json:"account_number,omitempty"`
+	AlternativeNames           []string                    `json:"alternative_names,omitempty"`
+	BankID                     string                      `json:"bank_id,omitempty"`
+	BankIDCode                 string                      `json:"bank_id_code,omitempty"`
+	BaseCurrency               string                      `json:"base_currency,omitempty"`
+	Bic                        string                      `json:"bic,omitempty"`
+	Country                    *string                     `json:"country,omitempty"`
+	CustomerID                 string                      `json:"customer_id,omitempty"`
+	Iban                       string                      `json:"iban,omitempty"`
+	JointAccount               *bool                       `json:"joint_account,omitempty"`
+	Name                       []string                    `json:"name,omitempty"`
+	NameMatchingStatus         string                      `json:"name_matching_status,omitempty"`
+	OrganisationIdentification *OrganisationIdentification `json:"organisation_identification,omitempty"`
+	PrivateIdentification      *PrivateIdentification      `json:"private_identification,omitempty"`
+	ProcessingService          string                      `json:"processing_service,omitempty"`
+	ReferenceMask              string                      `json:"reference_mask,omitempty"`
+	SecondaryIdentification    string                      `json:"secondary_identification,omitempty"`
+	Status                     *string                     `json:"status,omitempty"`
+	Switched                   *bool                       `json:"switched,omitempty"`
+	UserDefinedInformation     string                      `json:"user_defined_information,omitempty"`
+	ValidationType             string                      `json:"validation_type,omitempty"`
+}
+
+// PrivateIdentification identifies the individual holding a personal account.
+type PrivateIdentification struct {
+	Address        []string `json:"address,omitempty"`
+	BirthCountry   string   `json:"birth_country,omitempty"`
+	BirthDate      string   `json:"birth_date,omitempty"`
+	City           string   `json:"city,omitempty"`
+	Country        string   `json:"country,omitempty"`
+	Identification string   `json:"identification,omitempty"`
+}
+
+// OrganisationIdentification identifies the organisation holding a business account.
+type OrganisationIdentification struct {
+	Actors         []OrganisationActor `json:"actors,omitempty"`
+	Address        []string            `json:"address,omitempty"`
+	City           string              `json:"city,omitempty"`
+	Country        string              `json:"country,omitempty"`
+	Identification string              `json:"identification,omitempty"`
+}
+
+// OrganisationActor is a person acting on behalf of an organisation.
+type OrganisationActor struct {
+	BirthDate string   `json:"birth_date,omitempty"`
+	Name      []string `json:"name,omitempty"`
+	Residency string   `json:"residency,omitempty"`
+}
+
+// AccountAttributesUpdate holds the account attributes that can be changed on an existing account.
+// Nil and empty fields are left out of the PATCH and keep their current value. AlternativeNames is the
+// only attribute that can be cleared, by pointing it to an empty slice: the name is required, and the
+// status and account_matching_opt_out always have a value.
+type AccountAttributesUpdate struct {
+	AccountMatchingOptOut *bool     `json:"account_matching_opt_out,omitempty"`
+	AlternativeNames      *[]string `json:"alternative_names,omitempty"`
+	Name                  []string  `json:"name,omitempty"`
+	Status                *string   `json:"status,omitempty"`
 }
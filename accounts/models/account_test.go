@@ -0,0 +1,117 @@
+package models_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const personalAccountJSON = `{
+	"id": "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+	"organisation_id": "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
+	"type": "accounts",
+	"version": 0,
+	"created_on": "2021-08-05T11:59:09.519Z",
+	"modified_on": "2021-08-05T11:59:09.519Z",
+	"attributes": {
+		"acceptance_qualifier": "same_day",
+		"account_classification": "Personal",
+		"account_matching_opt_out": false,
+		"account_number": "41426819",
+		"alternative_names": ["Sam Holder"],
+		"bank_id": "400300",
+		"bank_id_code": "GBDSC",
+		"base_currency": "GBP",
+		"bic": "NWBKGB22",
+		"country": "GB",
+		"customer_id": "customer-1",
+		"iban": "GB11NWBK40030041426819",
+		"joint_account": false,
+		"name": ["Samantha Holder"],
+		"name_matching_status": "supported",
+		"private_identification": {
+			"address": ["10 Avenue des Champs"],
+			"birth_country": "GB",
+			"birth_date": "2017-07-23",
+			"city": "London",
+			"country": "GB",
+			"identification": "13YH458762"
+		},
+		"processing_service": "ABC Bank",
+		"reference_mask": "############",
+		"secondary_identification": "A1B2C3D4",
+		"status": "confirmed",
+		"switched": false,
+		"user_defined_information": "Some important info",
+		"validation_type": "card"
+	}
+}`
+
+const businessAccountJSON = `{
+	"id": "bd27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+	"organisation_id": "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
+	"type": "accounts",
+	"version": 3,
+	"attributes": {
+		"account_classification": "Business",
+		"country": "GB",
+		"name": ["Acme Ltd"],
+		"organisation_identification": {
+			"actors": [{"birth_date": "2016-07-23", "name": ["Jeff Page"], "residency": "GB"}],
+			"address": ["10 Avenue des Champs"],
+			"city": "London",
+			"country": "GB",
+			"identification": "123654"
+		}
+	}
+}`
+
+func TestAccountDataRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name    string
+		payload string
+	}{
+		{name: "personal account", payload: personalAccountJSON},
+		{name: "business account", payload: businessAccountJSON},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var account models.AccountData
+			assert.NoError(t, json.Unmarshal([]byte(tc.payload), &account))
+
+			encoded, err := json.Marshal(account)
+			assert.NoError(t, err)
+			assert.JSONEq(t, tc.payload, string(encoded))
+		})
+	}
+}
+
+func TestAccountDataFields(t *testing.T) {
+	var account models.AccountData
+	assert.NoError(t, json.Unmarshal([]byte(personalAccountJSON), &account))
+
+	expectedTime := time.Date(2021, 8, 5, 11, 59, 9, 519000000, time.UTC)
+	assert.True(t, expectedTime.Equal(*account.CreatedOn))
+	assert.True(t, expectedTime.Equal(*account.ModifiedOn))
+
+	attributes := account.Attributes
+	assert.Equal(t, "ABC Bank", attributes.ProcessingService)
+	assert.Equal(t, "Some important info", attributes.UserDefinedInformation)
+	assert.Equal(t, "card", attributes.ValidationType)
+	assert.Equal(t, "############", attributes.ReferenceMask)
+	assert.Equal(t, "same_day", attributes.AcceptanceQualifier)
+	assert.Equal(t, "supported", attributes.NameMatchingStatus)
+	assert.Equal(t, "2017-07-23", attributes.PrivateIdentification.BirthDate)
+	assert.Equal(t, "13YH458762", attributes.PrivateIdentification.Identification)
+}
+
+func TestAccountDataInvalidTimestamp(t *testing.T) {
+	var account models.AccountData
+	err := json.Unmarshal([]byte(`{"created_on": "yesterday"}`), &account)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// ResourceLinks represents the links member of a JSON:API resource object.
+type ResourceLinks struct {
+	Self string `json:"self,omitempty"`
+}
+
+// ResourceIdentifier identifies a related JSON:API resource.
+type ResourceIdentifier struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// Relationship represents a JSON:API relationship object. Data holds the related resources
+// whether the API sends a single resource identifier or an array of them.
+type Relationship struct {
+	Data  []ResourceIdentifier   `json:"data,omitempty"`
+	Links map[string]string      `json:"links,omitempty"`
+	Meta  map[string]interface{} `json:"meta,omitempty"`
+	// ToOne records a to-one relationship, whose data is a single resource identifier or null.
+	// It is set when decoding, so the relationship is encoded back in the same shape.
+	ToOne bool `json:"-"`
+}
+
+// MarshalJSON encodes the data of a to-one relationship as a single resource identifier, or null when
+// it is empty, and the data of a to-many relationship as an array.
+func (r Relationship) MarshalJSON() ([]byte, error) {
+	raw := struct {
+		Data  json.RawMessage        `json:"data,omitempty"`
+		Links map[string]string      `json:"links,omitempty"`
+		Meta  map[string]interface{} `json:"meta,omitempty"`
+	}{Links: r.Links, Meta: r.Meta}
+
+	var err error
+
+	switch {
+	case r.ToOne && len(r.Data) == 0:
+		raw.Data = json.RawMessage("null")
+	case r.ToOne && len(r.Data) == 1:
+		raw.Data, err = json.Marshal(r.Data[0])
+	case r.Data != nil:
+		raw.Data, err = json.Marshal(r.Data)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(raw)
+}
+
+// UnmarshalJSON decodes a relationship whose data is either a single resource identifier or an array,
+// ToOne records which one it was.
+func (r *Relationship) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Data  json.RawMessage        `json:"data"`
+		Links map[string]string      `json:"links"`
+		Meta  map[string]interface{} `json:"meta"`
+	}
+
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	r.Links = raw.Links
+	r.Meta = raw.Meta
+	r.Data = nil
+	r.ToOne = false
+
+	data := bytes.TrimSpace(raw.Data)
+
+	switch {
+	case len(data) == 0:
+		return nil
+	case bytes.Equal(data, []byte("null")):
+		r.ToOne = true
+
+		return nil
+	case data[0] == '[':
+		return json.Unmarshal(data, &r.Data)
+	default:
+		r.ToOne = true
+
+		var identifier ResourceIdentifier
+		if err := json.Unmarshal(data, &identifier); err != nil {
+			return err
+		}
+
+		r.Data = []ResourceIdentifier{identifier}
+
+		return nil
+	}
+}
@@ -0,0 +1,59 @@
+package models_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelationship(t *testing.T) {
+	t.Run("Decodes a single resource identifier", func(t *testing.T) {
+		var relationship models.Relationship
+		err := json.Unmarshal([]byte(`{"data": {"id": "event-id", "type": "account_events"}, "links": {"related": "/v1/events/event-id"}}`), &relationship)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []models.ResourceIdentifier{{ID: "event-id", Type: "account_events"}}, relationship.Data)
+		assert.Equal(t, "/v1/events/event-id", relationship.Links["related"])
+	})
+	t.Run("Decodes an array of resource identifiers", func(t *testing.T) {
+		var relationship models.Relationship
+		err := json.Unmarshal([]byte(`{"data": [{"id": "1", "type": "accounts"}, {"id": "2", "type": "accounts"}], "meta": {"count": 2}}`), &relationship)
+
+		assert.NoError(t, err)
+		assert.Len(t, relationship.Data, 2)
+		assert.Equal(t, float64(2), relationship.Meta["count"])
+	})
+	t.Run("Decodes a null relationship", func(t *testing.T) {
+		var relationship models.Relationship
+		err := json.Unmarshal([]byte(`{"data": null}`), &relationship)
+
+		assert.NoError(t, err)
+		assert.Nil(t, relationship.Data)
+	})
+	t.Run("Encodes back in the shape it was decoded from", func(t *testing.T) {
+		documents := []string{
+			`{"data": {"id": "event-id", "type": "account_events"}, "links": {"related": "/v1/events/event-id"}}`,
+			`{"data": [{"id": "1", "type": "accounts"}], "meta": {"count": 1}}`,
+			`{"data": []}`,
+			`{"data": null}`,
+			`{"links": {"self": "/v1/accounts/1/relationships/events"}}`,
+		}
+
+		for _, document := range documents {
+			var relationship models.Relationship
+			assert.NoError(t, json.Unmarshal([]byte(document), &relationship))
+
+			encoded, err := json.Marshal(relationship)
+			assert.NoError(t, err)
+			assert.JSONEq(t, document, string(encoded))
+		}
+	})
+	t.Run("Error on invalid data", func(t *testing.T) {
+		var relationship models.Relationship
+		err := json.Unmarshal([]byte(`{"data": "invalid"}`), &relationship)
+
+		assert.Error(t, err)
+	})
+}
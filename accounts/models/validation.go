@@ -0,0 +1,290 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
+	"github.com/aabri-assignments/form3-accounts/v1/pkg/iban"
+)
+
+// Presence tells whether a field must, may or must not be set for a country.
+type Presence int
+
+const (
+	Optional Presence = iota
+	Required
+	NotSupported
+)
+
+// CountryRule describes the bank details accepted for accounts held in a country.
+type CountryRule struct {
+	BankID              Presence
+	BankIDFormat        *regexp.Regexp
+	BankIDCode          Presence
+	AllowedBankIDCode   string
+	AccountNumberFormat *regexp.Regexp
+	BIC                 Presence
+	IBAN                Presence
+}
+
+var (
+	countryFormat      = regexp.MustCompile(`^[A-Z]{2}$`)
+	baseCurrencyFormat = regexp.MustCompile(`^[A-Z]{3}$`)
+	bicFormat          = regexp.MustCompile(`^([A-Z]{6}[A-Z0-9]{2}|[A-Z]{6}[A-Z0-9]{5})$`)
+)
+
+// CountryRules is the rule table used by AccountData.Validate, keyed by ISO 3166-1 country code.
+// Accounts in countries missing from the table are only checked for the common required fields.
+var CountryRules = map[string]CountryRule{
+	"AU": {
+		BankID:              Optional,
+		BankIDFormat:        regexp.MustCompile(`^\d{6}$`),
+		BankIDCode:          Required,
+		AllowedBankIDCode:   "AUBSB",
+		AccountNumberFormat: regexp.MustCompile(`^[1-9]\d{5,9}$`),
+		BIC:                 Required,
+		IBAN:                NotSupported,
+	},
+	"BE": {
+		BankID:              Required,
+		BankIDFormat:        regexp.MustCompile(`^\d{3}$`),
+		BankIDCode:          Required,
+		AllowedBankIDCode:   "BE",
+		AccountNumberFormat: regexp.MustCompile(`^\d{7}$`),
+		BIC:                 Optional,
+		IBAN:                Optional,
+	},
+	"CA": {
+		BankID:              Optional,
+		BankIDFormat:        regexp.MustCompile(`^0\d{8}$`),
+		BankIDCode:          Optional,
+		AllowedBankIDCode:   "CACPA",
+		AccountNumberFormat: regexp.MustCompile(`^\d{7,12}$`),
+		BIC:                 Required,
+		IBAN:                NotSupported,
+	},
+	"CH": {
+		BankID:              Required,
+		BankIDFormat:        regexp.MustCompile(`^\d{5}$`),
+		BankIDCode:          Required,
+		AllowedBankIDCode:   "CHBCC",
+		AccountNumberFormat: regexp.MustCompile(`^\d{12}$`),
+		BIC:                 Optional,
+		IBAN:                Optional,
+	},
+	"DE": {
+		BankID:              Required,
+		BankIDFormat:        regexp.MustCompile(`^\d{8}$`),
+		BankIDCode:          Required,
+		AllowedBankIDCode:   "DEBLZ",
+		AccountNumberFormat: regexp.MustCompile(`^\d{7}$`),
+		BIC:                 Optional,
+		IBAN:                Optional,
+	},
+	"ES": {
+		BankID:              Required,
+		BankIDFormat:        regexp.MustCompile(`^\d{8}$`),
+		BankIDCode:          Required,
+		AllowedBankIDCode:   "ESNCC",
+		AccountNumberFormat: regexp.MustCompile(`^\d{10}$`),
+		BIC:                 Optional,
+		IBAN:                Optional,
+	},
+	"FR": {
+		BankID:              Required,
+		BankIDFormat:        regexp.MustCompile(`^[A-Z0-9]{10}$`),
+		BankIDCode:          Required,
+		AllowedBankIDCode:   "FR",
+		AccountNumberFormat: regexp.MustCompile(`^[A-Z0-9]{10}$`),
+		BIC:                 Optional,
+		IBAN:                Optional,
+	},
+	"GB": {
+		BankID:              Required,
+		BankIDFormat:        regexp.MustCompile(`^\d{6}$`),
+		BankIDCode:          Required,
+		AllowedBankIDCode:   "GBDSC",
+		AccountNumberFormat: regexp.MustCompile(`^\d{8}$`),
+		BIC:                 Required,
+		IBAN:                Optional,
+	},
+	"GR": {
+		BankID:              Required,
+		BankIDFormat:        regexp.MustCompile(`^\d{7}$`),
+		BankIDCode:          Required,
+		AllowedBankIDCode:   "GRBIC",
+		AccountNumberFormat: regexp.MustCompile(`^\d{16}$`),
+		BIC:                 Optional,
+		IBAN:                Optional,
+	},
+	"HK": {
+		BankID:              Optional,
+		BankIDFormat:        regexp.MustCompile(`^\d{3}$`),
+		BankIDCode:          Required,
+		AllowedBankIDCode:   "HKNCC",
+		AccountNumberFormat: regexp.MustCompile(`^\d{9,12}$`),
+		BIC:                 Required,
+		IBAN:                NotSupported,
+	},
+	"IT": {
+		BankID:              Required,
+		BankIDFormat:        regexp.MustCompile(`^[A-Z0-9]{10,11}$`),
+		BankIDCode:          Required,
+		AllowedBankIDCode:   "ITNCC",
+		AccountNumberFormat: regexp.MustCompile(`^[A-Z0-9]{12}$`),
+		BIC:                 Optional,
+		IBAN:                Optional,
+	},
+	"LU": {
+		BankID:              Required,
+		BankIDFormat:        regexp.MustCompile(`^[A-Z0-9]{3}$`),
+		BankIDCode:          Required,
+		AllowedBankIDCode:   "LULUX",
+		AccountNumberFormat: regexp.MustCompile(`^[A-Z0-9]{13}$`),
+		BIC:                 Optional,
+		IBAN:                Optional,
+	},
+	"NL": {
+		BankID:              NotSupported,
+		BankIDCode:          NotSupported,
+		AccountNumberFormat: regexp.MustCompile(`^\d{10}$`),
+		BIC:                 Required,
+		IBAN:                Optional,
+	},
+	"PL": {
+		BankID:              Required,
+		BankIDFormat:        regexp.MustCompile(`^\d{8}$`),
+		BankIDCode:          Required,
+		AllowedBankIDCode:   "PLKNR",
+		AccountNumberFormat: regexp.MustCompile(`^\d{16}$`),
+		BIC:                 Optional,
+		IBAN:                Optional,
+	},
+	"PT": {
+		BankID:              Required,
+		BankIDFormat:        regexp.MustCompile(`^\d{8}$`),
+		BankIDCode:          Required,
+		AllowedBankIDCode:   "PTNCC",
+		AccountNumberFormat: regexp.MustCompile(`^\d{11}$`),
+		BIC:                 Optional,
+		IBAN:                Optional,
+	},
+	"US": {
+		BankID:              Required,
+		BankIDFormat:        regexp.MustCompile(`^\d{9}$`),
+		BankIDCode:          Required,
+		AllowedBankIDCode:   "USABA",
+		AccountNumberFormat: regexp.MustCompile(`^\d{6,17}$`),
+		BIC:                 Required,
+		IBAN:                NotSupported,
+	},
+}
+
+// Validate checks the account against the common required fields and the rules of its country.
+// It returns an *errors.ErrValidation listing every invalid field, or nil when the account is valid.
+func (a *AccountData) Validate() error {
+	var fields []errors.FieldError
+
+	invalid := func(field, reason string, args ...interface{}) {
+		fields = append(fields, errors.FieldError{Field: field, Reason: fmt.Sprintf(reason, args...)})
+	}
+
+	if a.ID == "" {
+		invalid("id", "is required")
+	}
+
+	if a.OrganisationID == "" {
+		invalid("organisation_id", "is required")
+	}
+
+	if a.Attributes == nil {
+		invalid("attributes", "is required")
+
+		return &errors.ErrValidation{Fields: fields}
+	}
+
+	attributes := a.Attributes
+
+	if len(attributes.Name) == 0 {
+		invalid("name", "is required")
+	}
+
+	if attributes.BaseCurrency != "" && !baseCurrencyFormat.MatchString(attributes.BaseCurrency) {
+		invalid("base_currency", "must be an ISO 4217 currency code")
+	}
+
+	if attributes.Bic != "" && !bicFormat.MatchString(attributes.Bic) {
+		invalid("bic", "must be a valid SWIFT BIC")
+	}
+
+	var country string
+	if attributes.Country != nil {
+		country = *attributes.Country
+	}
+
+	rule, hasRule := CountryRules[country]
+
+	if attributes.Iban != "" && (!hasRule || rule.IBAN != NotSupported) {
+		if err := iban.Validate(attributes.Iban); err != nil {
+			invalid("iban", "must be a valid IBAN, %s", err)
+		}
+	}
+
+	switch {
+	case country == "":
+		invalid("country", "is required")
+	case !countryFormat.MatchString(country):
+		invalid("country", "must be an ISO 3166-1 country code")
+	case hasRule:
+		fields = append(fields, rule.validate(country, attributes)...)
+	}
+
+	if len(fields) > 0 {
+		return &errors.ErrValidation{Fields: fields}
+	}
+
+	return nil
+}
+
+func (r CountryRule) validate(country string, attributes *AccountAttributes) []errors.FieldError {
+	var fields []errors.FieldError
+
+	invalid := func(field, reason string, args ...interface{}) {
+		fields = append(fields, errors.FieldError{Field: field, Reason: fmt.Sprintf(reason, args...)})
+	}
+
+	checkPresence := func(field, value string, presence Presence) bool {
+		switch {
+		case presence == Required && value == "":
+			invalid(field, "is required for country %s", country)
+		case presence == NotSupported && value != "":
+			invalid(field, "is not supported for country %s", country)
+		default:
+			return value != ""
+		}
+
+		return false
+	}
+
+	if checkPresence("bank_id", attributes.BankID, r.BankID) && r.BankIDFormat != nil && !r.BankIDFormat.MatchString(attributes.BankID) {
+		invalid("bank_id", "must match %s for country %s", r.BankIDFormat, country)
+	}
+
+	if checkPresence("bank_id_code", attributes.BankIDCode, r.BankIDCode) && attributes.BankIDCode != r.AllowedBankIDCode {
+		invalid("bank_id_code", "must be %s for country %s", r.AllowedBankIDCode, country)
+	}
+
+	if attributes.AccountNumber != "" && r.AccountNumberFormat != nil && !r.AccountNumberFormat.MatchString(attributes.AccountNumber) {
+		invalid("account_number", "must match %s for country %s", r.AccountNumberFormat, country)
+	}
+
+	checkPresence("bic", attributes.Bic, r.BIC)
+
+	if checkPresence("iban", attributes.Iban, r.IBAN) && iban.IsValid(attributes.Iban) && !strings.HasPrefix(attributes.Iban, country) {
+		invalid("iban", "must be an IBAN of country %s", country)
+	}
+
+	return fields
+}
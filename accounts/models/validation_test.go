@@ -0,0 +1,129 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func validAccount(country string, attributes models.AccountAttributes) *models.AccountData {
+	attributes.Country = &country
+	attributes.Name = []string{"Samantha Holder"}
+
+	return &models.AccountData{
+		ID:             "ad27e265-9605-4b4b-a0e5-3003ea9cc4dc",
+		OrganisationID: "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
+		Type:           "accounts",
+		Attributes:     &attributes,
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name           string
+		account        *models.AccountData
+		expectedFields []string
+	}{
+		{
+			name: "valid GB account",
+			account: validAccount("GB", models.AccountAttributes{
+				BankID: "601613", BankIDCode: "GBDSC", Bic: "NWBKGB22", AccountNumber: "31926819", Iban: "GB29NWBK60161331926819",
+			}),
+		},
+		{
+			name:    "valid NL account",
+			account: validAccount("NL", models.AccountAttributes{Bic: "ABNANL2A", AccountNumber: "0417164300"}),
+		},
+		{
+			name:    "valid account in a country without rules",
+			account: validAccount("JP", models.AccountAttributes{}),
+		},
+		{
+			name:           "missing attributes",
+			account:        &models.AccountData{},
+			expectedFields: []string{"id", "organisation_id", "attributes"},
+		},
+		{
+			name: "missing name and country",
+			account: &models.AccountData{
+				ID: "id", OrganisationID: "org", Attributes: &models.AccountAttributes{},
+			},
+			expectedFields: []string{"name", "country"},
+		},
+		{
+			name:           "GB account without bank details",
+			account:        validAccount("GB", models.AccountAttributes{}),
+			expectedFields: []string{"bank_id", "bank_id_code", "bic"},
+		},
+		{
+			name: "GB account with invalid formats",
+			account: validAccount("GB", models.AccountAttributes{
+				BankID: "4003", BankIDCode: "DEBLZ", Bic: "NWBKGB22", AccountNumber: "invalid", BaseCurrency: "pounds",
+			}),
+			expectedFields: []string{"base_currency", "bank_id", "bank_id_code", "account_number"},
+		},
+		{
+			name: "GB account with a foreign IBAN",
+			account: validAccount("GB", models.AccountAttributes{
+				BankID: "400300", BankIDCode: "GBDSC", Bic: "NWBKGB22", Iban: "DE89370400440532013000",
+			}),
+			expectedFields: []string{"iban"},
+		},
+		{
+			name:           "invalid IBAN and BIC",
+			account:        validAccount("GB", models.AccountAttributes{BankID: "400300", BankIDCode: "GBDSC", Bic: "invalid", Iban: "invalid"}),
+			expectedFields: []string{"bic", "iban"},
+		},
+		{
+			name: "US account with an IBAN",
+			account: validAccount("US", models.AccountAttributes{
+				BankID: "021000021", BankIDCode: "USABA", Bic: "CHASUS33", Iban: "US89370400440532013000",
+			}),
+			expectedFields: []string{"iban"},
+		},
+		{
+			name:           "NL account with a bank ID",
+			account:        validAccount("NL", models.AccountAttributes{BankID: "123", Bic: "ABNANL2A"}),
+			expectedFields: []string{"bank_id"},
+		},
+		{
+			name:           "invalid country code",
+			account:        validAccount("GBR", models.AccountAttributes{}),
+			expectedFields: []string{"country"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.account.Validate()
+			if tc.expectedFields == nil {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			validationErr, ok := err.(*errors.ErrValidation)
+			assert.True(t, ok, "Expected ErrValidation")
+
+			fields := make([]string, 0, len(validationErr.Fields))
+			for _, field := range validationErr.Fields {
+				fields = append(fields, field.Field)
+			}
+			assert.Equal(t, tc.expectedFields, fields)
+		})
+	}
+}
+
+func TestCountryRules(t *testing.T) {
+	for country, rule := range models.CountryRules {
+		assert.NotNil(t, rule.AccountNumberFormat, "Expected an account number format for %s", country)
+		if rule.BankID != models.NotSupported {
+			assert.NotNil(t, rule.BankIDFormat, "Expected a bank ID format for %s", country)
+		}
+		if rule.BankIDCode != models.NotSupported {
+			assert.NotEmpty(t, rule.AllowedBankIDCode, "Expected a bank ID code for %s", country)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package retry
+
+import (
+	"context"
+	errs "errors"
+	"net"
+	"net/http"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
+)
+
+// Classifier decides whether an operation that failed with an error is attempted again.
+type Classifier interface {
+	Retryable(err error) bool
+}
+
+// ClassifierFunc adapts a function to the Classifier interface.
+type ClassifierFunc func(err error) bool
+
+func (f ClassifierFunc) Retryable(err error) bool {
+	return f(err)
+}
+
+// DefaultClassifier retries network errors, rate limiting, 429 Too Many Requests and 5xx answers.
+// Permanent failures, conflicts, validation errors, exhausted time budgets, other 4xx answers, context errors
+// and rate limiting marked FailFast are not retried, and errors it does not know about are retried.
+var DefaultClassifier Classifier = ClassifierFunc(IsRetryable)
+
+// IsRetryable is the policy of DefaultClassifier.
+func IsRetryable(err error) bool {
+	var (
+		permanent   *errors.ErrPermanentFailure
+		conflict    *errors.ErrConflict
+		badRequest  *errors.ErrBadRequest
+		notFound    *errors.ErrNotFound
+		validation  *errors.ErrValidation
+		exhausted   *errors.ErrBudgetExhausted
+		rateLimited *errors.ErrRateLimited
+		network     *errors.ErrNetwork
+		apiErr      *errors.APIError
+		netErr      net.Error
+	)
+
+	switch {
+	case err == nil:
+		return false
+	case errs.Is(err, context.Canceled), errs.Is(err, context.DeadlineExceeded):
+		return false
+	case errs.As(err, &permanent), errs.As(err, &conflict), errs.As(err, &badRequest),
+		errs.As(err, &notFound), errs.As(err, &validation), errs.As(err, &exhausted):
+		return false
+	case errs.As(err, &rateLimited) && rateLimited.FailFast:
+		return false
+	case errs.As(err, &rateLimited), errs.As(err, &network), errs.As(err, &netErr):
+		return true
+	case errs.As(err, &apiErr):
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= http.StatusInternalServerError
+	default:
+		return true
+	}
+}
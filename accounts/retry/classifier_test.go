@@ -0,0 +1,119 @@
+package retry_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/retry"
+	errs "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, io.ErrUnexpectedEOF
+}
+
+func response(statusCode int, body string) *http.Response {
+	return &http.Response{StatusCode: statusCode, Body: io.NopCloser(bytes.NewBufferString(body))}
+}
+
+func TestDefaultClassifierHTTPErrors(t *testing.T) {
+	testCases := []struct {
+		name      string
+		response  *http.Response
+		retryable bool
+	}{
+		{"400 Bad Request", response(http.StatusBadRequest, `{"error_message": "validation failure"}`), false},
+		{"401 Unauthorized", response(http.StatusUnauthorized, `{"error_message": "invalid token"}`), false},
+		{"403 Forbidden", response(http.StatusForbidden, `{"error_message": "forbidden"}`), false},
+		{"404 Not Found", response(http.StatusNotFound, `{"error_message": "record does not exist"}`), false},
+		{"409 Conflict", response(http.StatusConflict, `{"error_message": "invalid version"}`), false},
+		{"422 Unprocessable Entity", response(http.StatusUnprocessableEntity, `{}`), false},
+		{"429 Too Many Requests", response(http.StatusTooManyRequests, `{"error_message": "slow down"}`), true},
+		{"500 Internal Server Error", response(http.StatusInternalServerError, `{"error_message": "boom"}`), true},
+		{"502 Bad Gateway without a JSON body", response(http.StatusBadGateway, `<html>Bad Gateway</html>`), true},
+		{"503 Service Unavailable", response(http.StatusServiceUnavailable, ``), true},
+		{"504 Gateway Timeout", response(http.StatusGatewayTimeout, `{}`), true},
+		{"Unreadable body", &http.Response{StatusCode: http.StatusInternalServerError, Body: io.NopCloser(failingReader{})}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := errors.HandleHTTPError(tc.response)
+			assert.Error(t, err)
+			assert.Equal(t, tc.retryable, retry.DefaultClassifier.Retryable(err), "%T: %v", err, err)
+		})
+	}
+
+	t.Run("2xx", func(t *testing.T) {
+		assert.NoError(t, errors.HandleHTTPError(response(http.StatusOK, ``)))
+		assert.False(t, retry.DefaultClassifier.Retryable(nil))
+	})
+}
+
+func TestDefaultClassifier(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+
+	testCases := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"Connection refused", &errors.ErrNetwork{Err: refused}, true},
+		{"Unwrapped network error", fmt.Errorf("failed to send HTTP request: %w", refused), true},
+		{"Rate limited", &errors.ErrRateLimited{Wait: time.Second}, true},
+		{"Rate limited without waiting", &errors.ErrRateLimited{Wait: time.Second, FailFast: true}, false},
+		{"Permanent failure", &errors.ErrPermanentFailure{Detail: "failed to unmarshal response body"}, false},
+		{"Validation", &errors.ErrValidation{Fields: []errors.FieldError{{Field: "iban", Reason: "is invalid"}}}, false},
+		{"Budget exhausted", &errors.ErrBudgetExhausted{Budget: time.Minute, Elapsed: time.Minute, Err: &errors.ErrNetwork{Err: refused}}, false},
+		{"Canceled", fmt.Errorf("failed to send HTTP request: %w", context.Canceled), false},
+		{"Deadline exceeded", context.DeadlineExceeded, false},
+		{"Unknown error", errs.New("api is not up yet"), true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.retryable, retry.IsRetryable(tc.err))
+		})
+	}
+}
+
+func TestRetryWithClassifier(t *testing.T) {
+	logger := &mockLogger{}
+
+	t.Run("Does not retry errors the default classifier rejects", func(t *testing.T) {
+		attempts := 0
+		operation := func() error {
+			attempts++
+
+			return &errors.ErrNotFound{ResourceID: "123456"}
+		}
+
+		err := retry.RetryWithContext(context.Background(), operation, retry.NewConstantBackOff(time.Millisecond, 3), logger)
+		assert.Error(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+	t.Run("Uses the given classifier", func(t *testing.T) {
+		attempts := 0
+		operation := func() error {
+			attempts++
+
+			return &errors.ErrNotFound{ResourceID: "123456"}
+		}
+		retryNotFound := retry.ClassifierFunc(func(err error) bool { return true })
+
+		err := retry.RetryWithClassifier(context.Background(), operation, retry.NewConstantBackOff(time.Millisecond, 3), retryNotFound, logger)
+		assert.Error(t, err)
+		assert.Equal(t, 4, attempts)
+	})
+}
@@ -1,6 +1,7 @@
 package retry
 
 import (
+	"context"
 	errs "errors"
 	"math"
 	"math/rand"
@@ -11,14 +12,18 @@ import (
 )
 
 const (
-	defaultDuration          = 5 * time.Minute
 	defaultDelay             = 00 * time.Millisecond
 	defaultMultiplier        = 2
 	defaultFactor            = 0.1
 	defaultMaxNetworkRetries = 5
+	// DefaultMaxRetryAfter caps the delays the API asks for with Retry-After when Options.MaxRetryAfter is zero.
+	DefaultMaxRetryAfter = time.Minute
+	// DefaultMaxElapsedTime is the time budget of the exponential backoff and of the accounts client when none is set.
+	DefaultMaxElapsedTime = 5 * time.Minute
 )
 
-// Retrier is an interface that represents a backoff retry strategy.
+// Retrier is the retry state of a single operation: it counts the attempts and returns the delay before
+// the next one. A Retrier is not safe for concurrent use, each operation gets its own from a Policy.
 type Retrier interface {
 	Attempt() int
 	NextBackOff() time.Duration
@@ -26,13 +31,22 @@ type Retrier interface {
 	RemainingRetries() int
 }
 
+// Policy describes how operations are retried and creates the Retrier of each operation. A policy is shared
+// by every call of a client, so NewRetrier must be safe for concurrent use.
+type Policy interface {
+	NewRetrier() Retrier
+}
+
 // ExponentialBackOff implements a backoff policy for retrying an operation using exponential backoff.
+// It is both a Policy, never modified by the operations it retries, and the Retrier of a single operation.
 type ExponentialBackOff struct {
-	MaxElapsedTime   time.Duration
-	MaxRetries       int
-	InitialDelay     time.Duration
-	Multiplier       float64
-	RandFactor       float64
+	MaxElapsedTime time.Duration
+	MaxRetries     int
+	InitialDelay   time.Duration
+	Multiplier     float64
+	RandFactor     float64
+	// MaxDelay caps the delay before each retry, zero means no cap.
+	MaxDelay         time.Duration
 	attempt          int
 	remainingRetries int
 }
@@ -40,7 +54,7 @@ type ExponentialBackOff struct {
 // NewExponentialBackOff creates a new ExponentialBackOff with the specified parameters.
 func NewExponentialBackOff(maxElapsedTime time.Duration, maxNetworkRetries int, initialDelay time.Duration, multiplier, randFactor float64) *ExponentialBackOff {
 	if maxElapsedTime == 0 {
-		maxElapsedTime = defaultDuration
+		maxElapsedTime = DefaultMaxElapsedTime
 	}
 
 	if maxNetworkRetries == 0 {
@@ -70,6 +84,19 @@ func NewExponentialBackOff(maxElapsedTime time.Duration, maxNetworkRetries int,
 	}
 }
 
+// NewRetrier returns a fresh ExponentialBackOff with the parameters of b, for a single operation.
+func (b *ExponentialBackOff) NewRetrier() Retrier {
+	return &ExponentialBackOff{
+		MaxElapsedTime:   b.MaxElapsedTime,
+		MaxRetries:       b.MaxRetries,
+		InitialDelay:     b.InitialDelay,
+		Multiplier:       b.Multiplier,
+		RandFactor:       b.RandFactor,
+		MaxDelay:         b.MaxDelay,
+		remainingRetries: b.MaxRetries,
+	}
+}
+
 // Attempt returns the current attempt number.
 func (b *ExponentialBackOff) Attempt() int {
 	return b.attempt
@@ -91,12 +118,12 @@ func (b *ExponentialBackOff) NextBackOff() time.Duration {
 	backoff := float64(b.InitialDelay) * math.Pow(b.Multiplier, float64(b.attempt-1))
 	jitter := (rand.Float64()*2 - 1) * b.RandFactor * backoff //nolint:gosec
 
-	delay := time.Duration(backoff + jitter)
-	if delay > b.MaxElapsedTime {
-		return -1
-	}
+	return capped(backoff+jitter, b.MaxDelay)
+}
 
-	return delay
+// ElapsedTimeBudget returns MaxElapsedTime, the time the retry loop lets an operation spend across its attempts.
+func (b *ExponentialBackOff) ElapsedTimeBudget() time.Duration {
+	return b.MaxElapsedTime
 }
 
 // Reset resets the backoff attempts counter.
@@ -105,19 +132,142 @@ func (b *ExponentialBackOff) Reset() {
 	b.remainingRetries = b.MaxRetries
 }
 
-// Retry retries the provided function using the provided Retries strategy.
-func Retry(operation func() error, retries Retrier, logger logging.LeveledLogger) error {
+// ConstantBackOff implements a backoff policy that waits the same interval between every attempt.
+// Like ExponentialBackOff, it is both a Policy and the Retrier of a single operation.
+type ConstantBackOff struct {
+	Interval   time.Duration
+	MaxRetries int
+	// MaxDelay caps the interval, zero means no cap.
+	MaxDelay time.Duration
+	attempt  int
+}
+
+// NewConstantBackOff creates a new ConstantBackOff with the specified parameters.
+func NewConstantBackOff(interval time.Duration, maxRetries int) *ConstantBackOff {
+	if maxRetries == 0 {
+		maxRetries = defaultMaxNetworkRetries
+	}
+
+	return &ConstantBackOff{
+		Interval:   interval,
+		MaxRetries: maxRetries,
+	}
+}
+
+// NewRetrier returns a fresh ConstantBackOff with the parameters of b, for a single operation.
+func (b *ConstantBackOff) NewRetrier() Retrier {
+	return &ConstantBackOff{Interval: b.Interval, MaxRetries: b.MaxRetries, MaxDelay: b.MaxDelay}
+}
+
+// Attempt returns the current attempt number.
+func (b *ConstantBackOff) Attempt() int {
+	return b.attempt
+}
+
+func (b *ConstantBackOff) RemainingRetries() int {
+	return b.MaxRetries - b.attempt
+}
+
+// NextBackOff returns the fixed interval until the retries run out.
+func (b *ConstantBackOff) NextBackOff() time.Duration {
+	if b.attempt >= b.MaxRetries {
+		return -1
+	}
+
+	b.attempt++
+
+	return capped(float64(b.Interval), b.MaxDelay)
+}
+
+// Reset resets the backoff attempts counter.
+func (b *ConstantBackOff) Reset() {
+	b.attempt = 0
+}
+
+// Retry retries the provided function using the provided retry policy.
+func Retry(operation func() error, policy Policy, logger logging.LeveledLogger) error {
+	return RetryWithContext(context.Background(), operation, policy, logger)
+}
+
+// RetryWithContext retries the provided function using the provided retry policy until
+// it succeeds, fails with an error DefaultClassifier does not retry, runs out of retries or the context is done.
+// When the context is done the context error is returned, so callers can match it with errors.Is.
+// Every call gets its own Retrier from the policy, so a policy can be shared across goroutines.
+func RetryWithContext(ctx context.Context, operation func() error, policy Policy, logger logging.LeveledLogger) error {
+	return RetryWithClassifier(ctx, operation, policy, DefaultClassifier, logger)
+}
+
+// RetryWithClassifier retries like RetryWithContext, with classifier deciding which errors are retried.
+// A nil classifier means DefaultClassifier.
+func RetryWithClassifier(ctx context.Context, operation func() error, policy Policy, classifier Classifier, logger logging.LeveledLogger) error {
+	return RetryWithOptions(ctx, operation, policy, Options{Classifier: classifier}, logger)
+}
+
+// Options configures RetryWithOptions.
+type Options struct {
+	// Classifier decides which errors are retried, DefaultClassifier when nil.
+	Classifier Classifier
+	// MaxRetryAfter caps the delays the API asks for with Retry-After, DefaultMaxRetryAfter when zero.
+	MaxRetryAfter time.Duration
+	// MaxElapsedTime bounds the time spent from the first attempt to the start of the last one, whatever the
+	// strategy of the policy. The budget of the policy is used when zero, see Budgeted, and the time is not
+	// bounded when neither sets one.
+	MaxElapsedTime time.Duration
+	// Now returns the time the budget is measured with, time.Now when nil.
+	Now func() time.Time
+}
+
+// Budgeted is implemented by the policies that bound the time an operation spends across all of its attempts.
+type Budgeted interface {
+	ElapsedTimeBudget() time.Duration
+}
+
+// RetryWithOptions retries like RetryWithContext, configured by opts. When an error carries a delay asked
+// by the API, the next attempt waits for that delay, capped by opts.MaxRetryAfter, instead of the backoff delay.
+// When the next wait would end past the time budget, it stops and returns an *errors.ErrBudgetExhausted
+// wrapping the last error.
+func RetryWithOptions(ctx context.Context, operation func() error, policy Policy, opts Options, logger logging.LeveledLogger) error {
+	retries := policy.NewRetrier()
+
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	budget := opts.MaxElapsedTime
+	if budgeted, ok := policy.(Budgeted); ok && budget <= 0 {
+		budget = budgeted.ElapsedTimeBudget()
+	}
+
+	start := now()
+
+	classifier := opts.Classifier
+	if classifier == nil {
+		classifier = DefaultClassifier
+	}
+
+	maxRetryAfter := opts.MaxRetryAfter
+	if maxRetryAfter <= 0 {
+		maxRetryAfter = DefaultMaxRetryAfter
+	}
+
 	var err error
 
 	for {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
 		err = operation()
 		if err == nil {
 			break
 		}
 
-		var permErr *errors.ErrPermanentFailure
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 
-		if errs.As(err, &permErr) {
+		if !classifier.Retryable(err) {
 			break
 		}
 
@@ -126,11 +276,63 @@ func Retry(operation func() error, retries Retrier, logger logging.LeveledLogger
 			break
 		}
 
-		logger.Infof("Retrying..")
+		retryAfter, asked := RetryAfter(err)
+		if asked {
+			delay = retryAfter
+			if delay > maxRetryAfter {
+				delay = maxRetryAfter
+			}
+		}
+
+		if elapsed := now().Sub(start); budget > 0 && elapsed+delay > budget {
+			logger.Infof("Not retrying: the next attempt would start after %s, past the budget of %s", elapsed+delay, budget)
+
+			return &errors.ErrBudgetExhausted{Budget: budget, Elapsed: elapsed, Err: err}
+		}
+
+		if asked {
+			logger.Infof("Retrying in %s as asked by the API..", delay)
+		} else {
+			logger.Infof("Retrying..")
+		}
+
 		logger.Debugf("Remaining retries: %d", retries.RemainingRetries())
 
-		time.Sleep(delay)
+		if ctxErr := Wait(ctx, delay); ctxErr != nil {
+			return ctxErr
+		}
 	}
 
 	return err
 }
+
+// RetryAfter returns the delay an error asks to wait before the next attempt: the Retry-After of an
+// *errors.APIError, or the wait of an *errors.ErrRateLimited.
+func RetryAfter(err error) (time.Duration, bool) {
+	var (
+		apiErr      *errors.APIError
+		rateLimited *errors.ErrRateLimited
+	)
+
+	switch {
+	case errs.As(err, &apiErr) && apiErr.RetryAfter > 0:
+		return apiErr.RetryAfter, true
+	case errs.As(err, &rateLimited) && rateLimited.Wait > 0:
+		return rateLimited.Wait, true
+	default:
+		return 0, false
+	}
+}
+
+// Wait blocks for the given delay or until the context is done, whichever happens first.
+func Wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -1,6 +1,10 @@
 package retry_test
 
 import (
+	"context"
+	errors2 "errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -17,6 +21,15 @@ func (m *mockLogger) Warnf(format string, v ...interface{})     {}
 func (m *mockLogger) Infof(format string, args ...interface{})  {}
 func (m *mockLogger) Errorf(format string, args ...interface{}) {}
 
+type recordingLogger struct {
+	mockLogger
+	infos []string
+}
+
+func (r *recordingLogger) Infof(format string, args ...interface{}) {
+	r.infos = append(r.infos, fmt.Sprintf(format, args...))
+}
+
 func TestExponentialBackOff(t *testing.T) {
 	t.Run("NewExponentialBackOff uses default values", func(t *testing.T) {
 		backOff := retry.NewExponentialBackOff(0, 0, 0, 0, 0)
@@ -33,18 +46,13 @@ func TestExponentialBackOff(t *testing.T) {
 		}
 		assert.Equal(t, time.Duration(-1), backOff.NextBackOff())
 	})
-	t.Run("NextBackOff respects MaxElapsedTime", func(t *testing.T) {
+	t.Run("NextBackOff leaves MaxElapsedTime to the retry loop", func(t *testing.T) {
 		backOff := retry.NewExponentialBackOff(10*time.Millisecond, 5, 1*time.Millisecond, 2, 0.1)
-		foundMaxElapsedTime := false
 		for i := 0; i < 5; i++ {
-			delay := backOff.NextBackOff()
-			if delay == time.Duration(-1) {
-				foundMaxElapsedTime = true
-			} else {
-				assert.LessOrEqual(t, delay, backOff.MaxElapsedTime)
-			}
+			assert.NotEqual(t, time.Duration(-1), backOff.NextBackOff())
 		}
-		assert.True(t, foundMaxElapsedTime, "Expected to find delay with -1 when MaxElapsedTime is reached")
+		assert.Equal(t, time.Duration(-1), backOff.NextBackOff())
+		assert.Equal(t, 10*time.Millisecond, backOff.ElapsedTimeBudget())
 	})
 	t.Run("Attempt and Reset work correctly", func(t *testing.T) {
 		backOff := retry.NewExponentialBackOff(5*time.Minute, 3, 10*time.Millisecond, 2, 0.1)
@@ -67,6 +75,24 @@ func TestExponentialBackOff(t *testing.T) {
 	})
 }
 
+func TestConstantBackOff(t *testing.T) {
+	t.Run("NewConstantBackOff uses default retries", func(t *testing.T) {
+		backOff := retry.NewConstantBackOff(time.Second, 0)
+		assert.Equal(t, 5, backOff.MaxRetries)
+	})
+	t.Run("NextBackOff returns the interval until MaxRetries", func(t *testing.T) {
+		backOff := retry.NewConstantBackOff(10*time.Millisecond, 2)
+		assert.Equal(t, 10*time.Millisecond, backOff.NextBackOff())
+		assert.Equal(t, 10*time.Millisecond, backOff.NextBackOff())
+		assert.Equal(t, time.Duration(-1), backOff.NextBackOff())
+		assert.Equal(t, 0, backOff.RemainingRetries())
+
+		backOff.Reset()
+		assert.Equal(t, 0, backOff.Attempt())
+		assert.Equal(t, 2, backOff.RemainingRetries())
+	})
+}
+
 func TestRetry(t *testing.T) {
 	logger := &mockLogger{}
 	temporaryError := errs.New("temporary error")
@@ -108,13 +134,327 @@ func TestRetry(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, 3, attempts)
 	})
-	t.Run("Respects remaining retries", func(t *testing.T) {
+	t.Run("Gives every call its own retries", func(t *testing.T) {
+		attempts := 0
 		operation := func() error {
+			attempts++
 			return temporaryError
 		}
 		backOff := retry.NewExponentialBackOff(5*time.Minute, 2, 10*time.Millisecond, 2, 0.1)
-		err := retry.Retry(operation, backOff, logger)
+
+		for call := 1; call <= 3; call++ {
+			err := retry.Retry(operation, backOff, logger)
+			assert.Error(t, err)
+			assert.Equal(t, 3*call, attempts)
+		}
+
+		assert.Equal(t, 0, backOff.Attempt(), "Expected the policy not to be modified")
+		assert.Equal(t, 2, backOff.RemainingRetries())
+	})
+}
+
+func TestPolicy(t *testing.T) {
+	policies := map[string]retry.Policy{
+		"ExponentialBackOff": retry.NewExponentialBackOff(5*time.Minute, 3, time.Millisecond, 2, 0.1),
+		"ConstantBackOff":    retry.NewConstantBackOff(time.Millisecond, 3),
+	}
+
+	for name, policy := range policies {
+		t.Run(name+" creates fresh retriers", func(t *testing.T) {
+			first := policy.NewRetrier()
+			first.NextBackOff()
+			first.NextBackOff()
+
+			second := policy.NewRetrier()
+			assert.Equal(t, 0, second.Attempt())
+			assert.Equal(t, 3, second.RemainingRetries())
+			assert.Equal(t, 2, first.Attempt())
+		})
+		t.Run(name+" is shared across goroutines", func(t *testing.T) {
+			var wg sync.WaitGroup
+
+			errs := make([]error, 20)
+			for i := range errs {
+				wg.Add(1)
+
+				go func(i int) {
+					defer wg.Done()
+
+					attempts := 0
+					errs[i] = retry.RetryWithContext(context.Background(), func() error {
+						attempts++
+						if attempts <= 3 {
+							return &errors.ErrNetwork{Err: errors2.New("connection refused")}
+						}
+
+						return nil
+					}, policy, &mockLogger{})
+				}(i)
+			}
+
+			wg.Wait()
+
+			for _, err := range errs {
+				assert.NoError(t, err, "Expected every call to get all of its retries")
+			}
+		})
+	}
+}
+
+func TestRetryWithContext(t *testing.T) {
+	logger := &mockLogger{}
+	temporaryError := errs.New("temporary error")
+
+	t.Run("Does not attempt when the context is already done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		attempts := 0
+		operation := func() error {
+			attempts++
+			return nil
+		}
+		backOff := retry.NewExponentialBackOff(5*time.Minute, 3, 10*time.Millisecond, 2, 0.1)
+		err := retry.RetryWithContext(ctx, operation, backOff, logger)
+		assert.True(t, errors2.Is(err, context.Canceled))
+		assert.Equal(t, 0, attempts)
+	})
+	t.Run("Stops waiting when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		attempts := 0
+		operation := func() error {
+			attempts++
+			cancel()
+			return temporaryError
+		}
+		backOff := retry.NewExponentialBackOff(5*time.Minute, 3, time.Minute, 2, 0.1)
+		start := time.Now()
+		err := retry.RetryWithContext(ctx, operation, backOff, logger)
+		assert.True(t, errors2.Is(err, context.Canceled))
+		assert.Equal(t, 1, attempts)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+	t.Run("Stops waiting when the deadline is exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		operation := func() error {
+			return temporaryError
+		}
+		backOff := retry.NewExponentialBackOff(5*time.Minute, 3, time.Minute, 2, 0.1)
+		start := time.Now()
+		err := retry.RetryWithContext(ctx, operation, backOff, logger)
+		assert.True(t, errors2.Is(err, context.DeadlineExceeded))
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	throttled := func(retryAfter time.Duration) error {
+		return &errors.APIError{StatusCode: 429, Message: "slow down", RetryAfter: retryAfter}
+	}
+
+	t.Run("Waits for the delay asked by the API", func(t *testing.T) {
+		logger := &recordingLogger{}
+		attempts := 0
+		operation := func() error {
+			attempts++
+			if attempts == 2 {
+				return nil
+			}
+
+			return throttled(50 * time.Millisecond)
+		}
+
+		start := time.Now()
+		err := retry.RetryWithOptions(context.Background(), operation, retry.NewConstantBackOff(0, 3), retry.Options{}, logger)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+		assert.Contains(t, logger.infos, "Retrying in 50ms as asked by the API..")
+	})
+	t.Run("Caps the delay with MaxRetryAfter", func(t *testing.T) {
+		logger := &recordingLogger{}
+		operation := func() error {
+			return throttled(time.Hour)
+		}
+
+		start := time.Now()
+		err := retry.RetryWithOptions(context.Background(), operation, retry.NewConstantBackOff(0, 2), retry.Options{MaxRetryAfter: 10 * time.Millisecond}, logger)
+		assert.Equal(t, throttled(time.Hour), err)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, []string{"Retrying in 10ms as asked by the API..", "Retrying in 10ms as asked by the API.."}, logger.infos)
+	})
+	t.Run("Stops waiting when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		operation := func() error {
+			return throttled(30 * time.Second)
+		}
+
+		start := time.Now()
+		err := retry.RetryWithContext(ctx, operation, retry.NewConstantBackOff(0, 3), &mockLogger{})
+		assert.True(t, errors2.Is(err, context.DeadlineExceeded))
+		assert.Less(t, time.Since(start), time.Second)
+	})
+	t.Run("Uses the backoff delay without a Retry-After", func(t *testing.T) {
+		logger := &recordingLogger{}
+		attempts := 0
+		operation := func() error {
+			attempts++
+			if attempts == 2 {
+				return nil
+			}
+
+			return throttled(0)
+		}
+
+		err := retry.RetryWithOptions(context.Background(), operation, retry.NewConstantBackOff(time.Millisecond, 3), retry.Options{}, logger)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Retrying.."}, logger.infos)
+	})
+	t.Run("Does not wait for errors that are not retried", func(t *testing.T) {
+		attempts := 0
+		operation := func() error {
+			attempts++
+
+			return &errors.APIError{StatusCode: 401, Message: "invalid token", RetryAfter: time.Hour}
+		}
+
+		err := retry.RetryWithContext(context.Background(), operation, retry.NewConstantBackOff(0, 3), &mockLogger{})
 		assert.Error(t, err)
-		assert.Equal(t, 0, backOff.RemainingRetries())
+		assert.Equal(t, 1, attempts)
+	})
+	t.Run("Reads the delay of errors", func(t *testing.T) {
+		delay, ok := retry.RetryAfter(fmt.Errorf("fetch: %w", throttled(time.Second)))
+		assert.True(t, ok)
+		assert.Equal(t, time.Second, delay)
+
+		delay, ok = retry.RetryAfter(&errors.ErrRateLimited{Wait: 200 * time.Millisecond})
+		assert.True(t, ok)
+		assert.Equal(t, 200*time.Millisecond, delay)
+
+		_, ok = retry.RetryAfter(errs.New("temporary error"))
+		assert.False(t, ok)
+	})
+}
+
+// fakeClock is a clock the operations of a test move forward.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestRetryBudget(t *testing.T) {
+	logger := &mockLogger{}
+	temporaryError := errs.New("temporary error")
+
+	t.Run("Stops once the time since the first attempt runs out", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)}
+		attempts := 0
+		operation := func() error {
+			attempts++
+			clock.now = clock.now.Add(2 * time.Minute)
+
+			return temporaryError
+		}
+
+		backOff := retry.NewExponentialBackOff(5*time.Minute, 10, time.Millisecond, 2, 0.1)
+		err := retry.RetryWithOptions(context.Background(), operation, backOff, retry.Options{Now: clock.Now}, logger)
+
+		var exhausted *errors.ErrBudgetExhausted
+		assert.True(t, errors2.As(err, &exhausted))
+		assert.Equal(t, 5*time.Minute, exhausted.Budget)
+		assert.Equal(t, 6*time.Minute, exhausted.Elapsed)
+		assert.True(t, errors2.Is(err, temporaryError))
+		assert.Equal(t, "retry budget of 5m0s exhausted after 6m0s: temporary error", err.Error())
+		assert.Equal(t, 3, attempts)
+	})
+	t.Run("Does not start a wait that would end past the budget", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)}
+		attempts := 0
+		operation := func() error {
+			attempts++
+			clock.now = clock.now.Add(5 * time.Second)
+
+			return &errors.APIError{StatusCode: 503, Message: "Service Unavailable", RetryAfter: 30 * time.Second}
+		}
+
+		start := time.Now()
+		opts := retry.Options{MaxElapsedTime: 20 * time.Second, Now: clock.Now}
+		err := retry.RetryWithOptions(context.Background(), operation, retry.NewConstantBackOff(0, 3), opts, logger)
+
+		var exhausted *errors.ErrBudgetExhausted
+		assert.True(t, errors2.As(err, &exhausted))
+		assert.Equal(t, 5*time.Second, exhausted.Elapsed)
+		assert.Equal(t, 1, attempts)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+	t.Run("MaxElapsedTime replaces the budget of the policy", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)}
+		attempts := 0
+		operation := func() error {
+			attempts++
+			clock.now = clock.now.Add(time.Minute)
+
+			return temporaryError
+		}
+
+		backOff := retry.NewExponentialBackOff(time.Hour, 10, time.Millisecond, 2, 0.1)
+		opts := retry.Options{MaxElapsedTime: 90 * time.Second, Now: clock.Now}
+		err := retry.RetryWithOptions(context.Background(), operation, backOff, opts, logger)
+
+		assert.IsType(t, &errors.ErrBudgetExhausted{}, err)
+		assert.Equal(t, 2, attempts)
+	})
+	t.Run("MaxElapsedTime bounds any strategy", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)}
+		attempts := 0
+		operation := func() error {
+			attempts++
+			clock.now = clock.now.Add(time.Minute)
+
+			return temporaryError
+		}
+
+		backOff := retry.NewFibonacciBackOff(time.Millisecond, 0, 10)
+		opts := retry.Options{MaxElapsedTime: 3 * time.Minute, Now: clock.Now}
+		err := retry.RetryWithOptions(context.Background(), operation, backOff, opts, logger)
+
+		var exhausted *errors.ErrBudgetExhausted
+		assert.True(t, errors2.As(err, &exhausted))
+		assert.Equal(t, 3*time.Minute, exhausted.Budget)
+		assert.Equal(t, 3, attempts)
+	})
+	t.Run("Policies without a budget are not bounded", func(t *testing.T) {
+		clock := &fakeClock{now: time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)}
+		attempts := 0
+		operation := func() error {
+			attempts++
+			clock.now = clock.now.Add(time.Hour)
+
+			return temporaryError
+		}
+
+		err := retry.RetryWithOptions(context.Background(), operation, retry.NewConstantBackOff(0, 3), retry.Options{Now: clock.Now}, logger)
+		assert.Equal(t, temporaryError, err)
+		assert.Equal(t, 4, attempts)
+	})
+	t.Run("Measures the wall clock by default", func(t *testing.T) {
+		operation := func() error {
+			return temporaryError
+		}
+
+		start := time.Now()
+		err := retry.RetryWithOptions(context.Background(), operation, retry.NewConstantBackOff(10*time.Millisecond, 100), retry.Options{MaxElapsedTime: 50 * time.Millisecond}, logger)
+
+		assert.IsType(t, &errors.ErrBudgetExhausted{}, err)
+		assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+		assert.Less(t, time.Since(start), time.Second)
 	})
 }
@@ -0,0 +1,310 @@
+package retry
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Names of the strategies created by NewStrategy.
+const (
+	StrategyExponential        = "exponential"
+	StrategyConstant           = "constant"
+	StrategyLinear             = "linear"
+	StrategyFullJitter         = "full-jitter"
+	StrategyEqualJitter        = "equal-jitter"
+	StrategyDecorrelatedJitter = "decorrelated-jitter"
+	StrategyFibonacci          = "fibonacci"
+)
+
+// StrategyOptions configures the strategy created by NewStrategy.
+type StrategyOptions struct {
+	// MaxRetries is the number of retries after the first attempt, 5 when zero.
+	MaxRetries int
+	// BaseDelay is the delay the strategy grows from.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay before each retry, zero means no cap.
+	MaxDelay time.Duration
+}
+
+// NewStrategy creates the retry policy with the given name, one of the Strategy constants.
+func NewStrategy(name string, opts StrategyOptions) (Policy, error) {
+	switch name {
+	case StrategyExponential:
+		backOff := NewExponentialBackOff(0, opts.MaxRetries, opts.BaseDelay, 0, 0)
+		backOff.MaxDelay = opts.MaxDelay
+
+		return backOff, nil
+	case StrategyConstant:
+		backOff := NewConstantBackOff(opts.BaseDelay, opts.MaxRetries)
+		backOff.MaxDelay = opts.MaxDelay
+
+		return backOff, nil
+	case StrategyLinear:
+		return NewLinearBackOff(opts.BaseDelay, opts.BaseDelay, opts.MaxDelay, opts.MaxRetries), nil
+	case StrategyFullJitter:
+		return NewFullJitterBackOff(opts.BaseDelay, opts.MaxDelay, opts.MaxRetries), nil
+	case StrategyEqualJitter:
+		return NewEqualJitterBackOff(opts.BaseDelay, opts.MaxDelay, opts.MaxRetries), nil
+	case StrategyDecorrelatedJitter:
+		return NewDecorrelatedJitterBackOff(opts.BaseDelay, opts.MaxDelay, opts.MaxRetries), nil
+	case StrategyFibonacci:
+		return NewFibonacciBackOff(opts.BaseDelay, opts.MaxDelay, opts.MaxRetries), nil
+	default:
+		return nil, fmt.Errorf("unknown retry strategy %q", name)
+	}
+}
+
+// counter counts the attempts of a Retrier.
+type counter struct {
+	attempt int
+}
+
+// Attempt returns the current attempt number.
+func (c *counter) Attempt() int {
+	return c.attempt
+}
+
+// Reset resets the backoff attempts counter.
+func (c *counter) Reset() {
+	c.attempt = 0
+}
+
+// next counts an attempt and reports whether it is within maxRetries.
+func (c *counter) next(maxRetries int) bool {
+	if c.attempt >= maxRetries {
+		return false
+	}
+
+	c.attempt++
+
+	return true
+}
+
+// LinearBackOff waits InitialDelay before the first retry and Increment more before each of the next ones.
+type LinearBackOff struct {
+	InitialDelay time.Duration
+	Increment    time.Duration
+	// MaxDelay caps the delay before each retry, zero means no cap.
+	MaxDelay   time.Duration
+	MaxRetries int
+	counter
+}
+
+// NewLinearBackOff creates a new LinearBackOff with the specified parameters.
+func NewLinearBackOff(initialDelay, increment, maxDelay time.Duration, maxRetries int) *LinearBackOff {
+	if maxRetries == 0 {
+		maxRetries = defaultMaxNetworkRetries
+	}
+
+	return &LinearBackOff{InitialDelay: initialDelay, Increment: increment, MaxDelay: maxDelay, MaxRetries: maxRetries}
+}
+
+// NewRetrier returns a fresh LinearBackOff with the parameters of b, for a single operation.
+func (b *LinearBackOff) NewRetrier() Retrier {
+	return &LinearBackOff{InitialDelay: b.InitialDelay, Increment: b.Increment, MaxDelay: b.MaxDelay, MaxRetries: b.MaxRetries}
+}
+
+func (b *LinearBackOff) RemainingRetries() int {
+	return b.MaxRetries - b.attempt
+}
+
+// NextBackOff returns InitialDelay plus Increment for every earlier retry.
+func (b *LinearBackOff) NextBackOff() time.Duration {
+	if !b.next(b.MaxRetries) {
+		return -1
+	}
+
+	return capped(float64(b.InitialDelay)+float64(b.Increment)*float64(b.attempt-1), b.MaxDelay)
+}
+
+// FullJitterBackOff waits a random delay between zero and an exponentially growing ceiling:
+// BaseDelay doubled for every earlier retry, capped by MaxDelay.
+type FullJitterBackOff struct {
+	BaseDelay time.Duration
+	// MaxDelay caps the delay before each retry, zero means no cap.
+	MaxDelay   time.Duration
+	MaxRetries int
+	counter
+}
+
+// NewFullJitterBackOff creates a new FullJitterBackOff with the specified parameters.
+func NewFullJitterBackOff(baseDelay, maxDelay time.Duration, maxRetries int) *FullJitterBackOff {
+	if maxRetries == 0 {
+		maxRetries = defaultMaxNetworkRetries
+	}
+
+	return &FullJitterBackOff{BaseDelay: baseDelay, MaxDelay: maxDelay, MaxRetries: maxRetries}
+}
+
+// NewRetrier returns a fresh FullJitterBackOff with the parameters of b, for a single operation.
+func (b *FullJitterBackOff) NewRetrier() Retrier {
+	return &FullJitterBackOff{BaseDelay: b.BaseDelay, MaxDelay: b.MaxDelay, MaxRetries: b.MaxRetries}
+}
+
+func (b *FullJitterBackOff) RemainingRetries() int {
+	return b.MaxRetries - b.attempt
+}
+
+// NextBackOff returns a delay drawn uniformly below the ceiling of the attempt.
+func (b *FullJitterBackOff) NextBackOff() time.Duration {
+	if !b.next(b.MaxRetries) {
+		return -1
+	}
+
+	ceiling := doubled(b.BaseDelay, b.attempt, b.MaxDelay)
+
+	return time.Duration(rand.Float64() * float64(ceiling)) //nolint:gosec
+}
+
+// EqualJitterBackOff waits half of an exponentially growing ceiling plus a random delay up to the other half.
+// The ceiling is BaseDelay doubled for every earlier retry, capped by MaxDelay.
+type EqualJitterBackOff struct {
+	BaseDelay time.Duration
+	// MaxDelay caps the delay before each retry, zero means no cap.
+	MaxDelay   time.Duration
+	MaxRetries int
+	counter
+}
+
+// NewEqualJitterBackOff creates a new EqualJitterBackOff with the specified parameters.
+func NewEqualJitterBackOff(baseDelay, maxDelay time.Duration, maxRetries int) *EqualJitterBackOff {
+	if maxRetries == 0 {
+		maxRetries = defaultMaxNetworkRetries
+	}
+
+	return &EqualJitterBackOff{BaseDelay: baseDelay, MaxDelay: maxDelay, MaxRetries: maxRetries}
+}
+
+// NewRetrier returns a fresh EqualJitterBackOff with the parameters of b, for a single operation.
+func (b *EqualJitterBackOff) NewRetrier() Retrier {
+	return &EqualJitterBackOff{BaseDelay: b.BaseDelay, MaxDelay: b.MaxDelay, MaxRetries: b.MaxRetries}
+}
+
+func (b *EqualJitterBackOff) RemainingRetries() int {
+	return b.MaxRetries - b.attempt
+}
+
+// NextBackOff returns a delay drawn uniformly between half the ceiling of the attempt and the ceiling.
+func (b *EqualJitterBackOff) NextBackOff() time.Duration {
+	if !b.next(b.MaxRetries) {
+		return -1
+	}
+
+	half := float64(doubled(b.BaseDelay, b.attempt, b.MaxDelay)) / 2
+
+	return time.Duration(half + rand.Float64()*half) //nolint:gosec
+}
+
+// DecorrelatedJitterBackOff waits a random delay between BaseDelay and three times the previous delay,
+// capped by MaxDelay. The delays grow like an exponential backoff without the retries of concurrent
+// callers lining up.
+type DecorrelatedJitterBackOff struct {
+	BaseDelay time.Duration
+	// MaxDelay caps the delay before each retry, zero means no cap.
+	MaxDelay   time.Duration
+	MaxRetries int
+	counter
+	previous time.Duration
+}
+
+// NewDecorrelatedJitterBackOff creates a new DecorrelatedJitterBackOff with the specified parameters.
+func NewDecorrelatedJitterBackOff(baseDelay, maxDelay time.Duration, maxRetries int) *DecorrelatedJitterBackOff {
+	if maxRetries == 0 {
+		maxRetries = defaultMaxNetworkRetries
+	}
+
+	return &DecorrelatedJitterBackOff{BaseDelay: baseDelay, MaxDelay: maxDelay, MaxRetries: maxRetries}
+}
+
+// NewRetrier returns a fresh DecorrelatedJitterBackOff with the parameters of b, for a single operation.
+func (b *DecorrelatedJitterBackOff) NewRetrier() Retrier {
+	return &DecorrelatedJitterBackOff{BaseDelay: b.BaseDelay, MaxDelay: b.MaxDelay, MaxRetries: b.MaxRetries}
+}
+
+func (b *DecorrelatedJitterBackOff) RemainingRetries() int {
+	return b.MaxRetries - b.attempt
+}
+
+// NextBackOff returns a delay drawn uniformly between BaseDelay and three times the previous delay.
+func (b *DecorrelatedJitterBackOff) NextBackOff() time.Duration {
+	if !b.next(b.MaxRetries) {
+		return -1
+	}
+
+	previous := b.previous
+	if previous < b.BaseDelay {
+		previous = b.BaseDelay
+	}
+
+	low, high := float64(b.BaseDelay), 3*float64(previous)
+	b.previous = capped(low+rand.Float64()*(high-low), b.MaxDelay) //nolint:gosec
+
+	return b.previous
+}
+
+// Reset resets the backoff attempts counter and the previous delay.
+func (b *DecorrelatedJitterBackOff) Reset() {
+	b.counter.Reset()
+	b.previous = 0
+}
+
+// FibonacciBackOff waits BaseDelay times the Fibonacci numbers 1, 1, 2, 3, 5, 8... before the retries.
+type FibonacciBackOff struct {
+	BaseDelay time.Duration
+	// MaxDelay caps the delay before each retry, zero means no cap.
+	MaxDelay   time.Duration
+	MaxRetries int
+	counter
+}
+
+// NewFibonacciBackOff creates a new FibonacciBackOff with the specified parameters.
+func NewFibonacciBackOff(baseDelay, maxDelay time.Duration, maxRetries int) *FibonacciBackOff {
+	if maxRetries == 0 {
+		maxRetries = defaultMaxNetworkRetries
+	}
+
+	return &FibonacciBackOff{BaseDelay: baseDelay, MaxDelay: maxDelay, MaxRetries: maxRetries}
+}
+
+// NewRetrier returns a fresh FibonacciBackOff with the parameters of b, for a single operation.
+func (b *FibonacciBackOff) NewRetrier() Retrier {
+	return &FibonacciBackOff{BaseDelay: b.BaseDelay, MaxDelay: b.MaxDelay, MaxRetries: b.MaxRetries}
+}
+
+func (b *FibonacciBackOff) RemainingRetries() int {
+	return b.MaxRetries - b.attempt
+}
+
+// NextBackOff returns BaseDelay times the Fibonacci number of the attempt.
+func (b *FibonacciBackOff) NextBackOff() time.Duration {
+	if !b.next(b.MaxRetries) {
+		return -1
+	}
+
+	previous, current := 0.0, 1.0
+	for i := 1; i < b.attempt; i++ {
+		previous, current = current, previous+current
+	}
+
+	return capped(float64(b.BaseDelay)*current, b.MaxDelay)
+}
+
+// doubled returns baseDelay doubled for every attempt before the given one, capped by maxDelay.
+func doubled(baseDelay time.Duration, attempt int, maxDelay time.Duration) time.Duration {
+	return capped(float64(baseDelay)*math.Pow(2, float64(attempt-1)), maxDelay)
+}
+
+// capped converts a delay in nanoseconds to a time.Duration, capped by maxDelay when it is positive.
+func capped(delay float64, maxDelay time.Duration) time.Duration {
+	if maxDelay > 0 && delay > float64(maxDelay) {
+		return maxDelay
+	}
+
+	if delay >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+
+	return time.Duration(delay)
+}
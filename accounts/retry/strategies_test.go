@@ -0,0 +1,258 @@
+package retry_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// samples is the number of operations each distribution is measured over.
+const samples = 10000
+
+// delays returns the delays before every retry of samples operations, grouped by attempt.
+func delays(t *testing.T, policy retry.Policy) [][]time.Duration {
+	t.Helper()
+
+	var byAttempt [][]time.Duration
+
+	for i := 0; i < samples; i++ {
+		retrier := policy.NewRetrier()
+
+		for attempt := 0; ; attempt++ {
+			delay := retrier.NextBackOff()
+			if delay == -1 {
+				break
+			}
+
+			if attempt == len(byAttempt) {
+				byAttempt = append(byAttempt, make([]time.Duration, 0, samples))
+			}
+
+			byAttempt[attempt] = append(byAttempt[attempt], delay)
+		}
+	}
+
+	return byAttempt
+}
+
+// uniformity checks that the delays are spread uniformly over [low, high).
+func uniformity(t *testing.T, attempt int, values []time.Duration, low, high time.Duration) {
+	t.Helper()
+
+	var sum float64
+
+	quartiles := make([]int, 4)
+
+	for _, value := range values {
+		require.GreaterOrEqual(t, value, low, "attempt %d", attempt)
+		require.LessOrEqual(t, value, high, "attempt %d", attempt)
+
+		position := float64(value-low) / float64(high-low)
+		sum += position
+		quartiles[int(math.Min(position*4, 3))]++
+	}
+
+	assert.InDelta(t, 0.5, sum/float64(len(values)), 0.02, "mean of attempt %d", attempt)
+
+	for quartile, count := range quartiles {
+		assert.InDelta(t, 0.25, float64(count)/float64(len(values)), 0.02, "quartile %d of attempt %d", quartile, attempt)
+	}
+}
+
+func TestStrategyDistributions(t *testing.T) {
+	t.Run("Constant", func(t *testing.T) {
+		backOff := retry.NewConstantBackOff(100*time.Millisecond, 4)
+
+		for _, values := range delays(t, backOff) {
+			for _, value := range values {
+				assert.Equal(t, 100*time.Millisecond, value)
+			}
+		}
+
+		backOff.MaxDelay = 30 * time.Millisecond
+		assert.Equal(t, 30*time.Millisecond, backOff.NewRetrier().NextBackOff())
+	})
+	t.Run("Linear", func(t *testing.T) {
+		expected := []time.Duration{100, 150, 200, 250, 250, 250}
+		byAttempt := delays(t, retry.NewLinearBackOff(100*time.Millisecond, 50*time.Millisecond, 250*time.Millisecond, 6))
+
+		require.Len(t, byAttempt, len(expected))
+
+		for attempt, values := range byAttempt {
+			for _, value := range values {
+				assert.Equal(t, expected[attempt]*time.Millisecond, value, "attempt %d", attempt)
+			}
+		}
+	})
+	t.Run("Fibonacci", func(t *testing.T) {
+		expected := []time.Duration{10, 10, 20, 30, 50, 80, 100, 100}
+		byAttempt := delays(t, retry.NewFibonacciBackOff(10*time.Millisecond, 100*time.Millisecond, 8))
+
+		require.Len(t, byAttempt, len(expected))
+
+		for attempt, values := range byAttempt {
+			for _, value := range values {
+				assert.Equal(t, expected[attempt]*time.Millisecond, value, "attempt %d", attempt)
+			}
+		}
+	})
+	t.Run("Exponential", func(t *testing.T) {
+		backOff := retry.NewExponentialBackOff(time.Hour, 5, 100*time.Millisecond, 2, 0.1)
+		backOff.MaxDelay = time.Second
+		byAttempt := delays(t, backOff)
+
+		require.Len(t, byAttempt, 5)
+
+		for attempt, values := range byAttempt[:4] {
+			center := 100 * time.Millisecond << attempt
+			uniformity(t, attempt, values, center-center/10, center+center/10)
+		}
+
+		for _, value := range byAttempt[4] {
+			assert.Equal(t, time.Second, value, "Expected 1.6s ± 10%% to be capped")
+		}
+	})
+	t.Run("Full jitter", func(t *testing.T) {
+		ceilings := []time.Duration{100, 200, 400, 800, 1000, 1000}
+		byAttempt := delays(t, retry.NewFullJitterBackOff(100*time.Millisecond, time.Second, 6))
+
+		require.Len(t, byAttempt, len(ceilings))
+
+		for attempt, values := range byAttempt {
+			uniformity(t, attempt, values, 0, ceilings[attempt]*time.Millisecond)
+		}
+	})
+	t.Run("Equal jitter", func(t *testing.T) {
+		ceilings := []time.Duration{100, 200, 400, 800, 1000, 1000}
+		byAttempt := delays(t, retry.NewEqualJitterBackOff(100*time.Millisecond, time.Second, 6))
+
+		require.Len(t, byAttempt, len(ceilings))
+
+		for attempt, values := range byAttempt {
+			uniformity(t, attempt, values, ceilings[attempt]*time.Millisecond/2, ceilings[attempt]*time.Millisecond)
+		}
+	})
+	t.Run("Decorrelated jitter", func(t *testing.T) {
+		base, maxDelay := 100*time.Millisecond, 2*time.Second
+		byAttempt := delays(t, retry.NewDecorrelatedJitterBackOff(base, maxDelay, 10))
+
+		require.Len(t, byAttempt, 10)
+		uniformity(t, 0, byAttempt[0], base, 3*base)
+
+		capped := 0
+
+		for attempt := 1; attempt < len(byAttempt); attempt++ {
+			for i, value := range byAttempt[attempt] {
+				previous := byAttempt[attempt-1][i]
+				require.GreaterOrEqual(t, value, base)
+				require.LessOrEqual(t, value, maxDelay)
+
+				if value < maxDelay {
+					require.LessOrEqual(t, value, 3*previous, "Expected at most three times the previous delay")
+				} else {
+					capped++
+				}
+			}
+		}
+
+		assert.Positive(t, capped, "Expected the delays to reach MaxDelay")
+
+		// Each delay is uniform over [base, 3*previous), so its mean given the previous delay is base/2 + 3*previous/2.
+		var residual float64
+
+		for i, value := range byAttempt[1] {
+			residual += float64(value) - (float64(base)/2 + 1.5*float64(byAttempt[0][i]))
+		}
+
+		assert.InDelta(t, 0, residual/samples/float64(base), 0.05)
+	})
+}
+
+func TestStrategyRetriers(t *testing.T) {
+	policies := map[string]retry.Policy{
+		"Linear":              retry.NewLinearBackOff(time.Millisecond, time.Millisecond, 0, 3),
+		"Full jitter":         retry.NewFullJitterBackOff(time.Millisecond, 0, 3),
+		"Equal jitter":        retry.NewEqualJitterBackOff(time.Millisecond, 0, 3),
+		"Decorrelated jitter": retry.NewDecorrelatedJitterBackOff(time.Millisecond, 0, 3),
+		"Fibonacci":           retry.NewFibonacciBackOff(time.Millisecond, 0, 3),
+	}
+
+	for name, policy := range policies {
+		t.Run(name, func(t *testing.T) {
+			retrier := policy.NewRetrier()
+			assert.Equal(t, 3, retrier.RemainingRetries())
+
+			for i := 1; i <= 3; i++ {
+				assert.GreaterOrEqual(t, retrier.NextBackOff(), time.Duration(0))
+				assert.Equal(t, i, retrier.Attempt())
+				assert.Equal(t, 3-i, retrier.RemainingRetries())
+			}
+
+			assert.Equal(t, time.Duration(-1), retrier.NextBackOff())
+
+			retrier.Reset()
+			assert.Equal(t, 0, retrier.Attempt())
+			assert.Equal(t, 3, retrier.RemainingRetries())
+			assert.Equal(t, 3, policy.NewRetrier().RemainingRetries(), "Expected the policy not to be modified")
+		})
+	}
+
+	t.Run("Delays without a cap do not overflow", func(t *testing.T) {
+		for _, policy := range []retry.Policy{
+			retry.NewFullJitterBackOff(time.Second, 0, 100),
+			retry.NewEqualJitterBackOff(time.Second, 0, 100),
+			retry.NewDecorrelatedJitterBackOff(time.Second, 0, 100),
+			retry.NewFibonacciBackOff(time.Second, 0, 100),
+			retry.NewLinearBackOff(time.Second, math.MaxInt64/2, 0, 100),
+		} {
+			retrier := policy.NewRetrier()
+
+			for delay := retrier.NextBackOff(); delay != -1; delay = retrier.NextBackOff() {
+				assert.GreaterOrEqual(t, delay, time.Duration(0), "%T", policy)
+			}
+		}
+	})
+}
+
+func TestNewStrategy(t *testing.T) {
+	opts := retry.StrategyOptions{MaxRetries: 4, BaseDelay: 10 * time.Millisecond, MaxDelay: time.Second}
+
+	testCases := []struct {
+		name     string
+		expected retry.Policy
+	}{
+		{retry.StrategyConstant, &retry.ConstantBackOff{Interval: 10 * time.Millisecond, MaxRetries: 4, MaxDelay: time.Second}},
+		{retry.StrategyLinear, retry.NewLinearBackOff(10*time.Millisecond, 10*time.Millisecond, time.Second, 4)},
+		{retry.StrategyFullJitter, retry.NewFullJitterBackOff(10*time.Millisecond, time.Second, 4)},
+		{retry.StrategyEqualJitter, retry.NewEqualJitterBackOff(10*time.Millisecond, time.Second, 4)},
+		{retry.StrategyDecorrelatedJitter, retry.NewDecorrelatedJitterBackOff(10*time.Millisecond, time.Second, 4)},
+		{retry.StrategyFibonacci, retry.NewFibonacciBackOff(10*time.Millisecond, time.Second, 4)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := retry.NewStrategy(tc.name, opts)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, policy)
+		})
+	}
+
+	t.Run(retry.StrategyExponential, func(t *testing.T) {
+		policy, err := retry.NewStrategy(retry.StrategyExponential, opts)
+		assert.NoError(t, err)
+
+		backOff, ok := policy.(*retry.ExponentialBackOff)
+		assert.True(t, ok)
+		assert.Equal(t, 4, backOff.MaxRetries)
+		assert.Equal(t, 10*time.Millisecond, backOff.InitialDelay)
+		assert.Equal(t, time.Second, backOff.MaxDelay)
+	})
+	t.Run("Unknown strategy", func(t *testing.T) {
+		_, err := retry.NewStrategy("quadratic", opts)
+		assert.EqualError(t, err, `unknown retry strategy "quadratic"`)
+	})
+}
@@ -0,0 +1,318 @@
+// Package cassette records the HTTP exchanges of the client to files and replays them, so tests can
+// run against the real wire format without the API. A Recorder is an http.RoundTripper, it is plugged
+// into the client with the RoundTripper field of http.Options.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	errors2 "github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
+)
+
+// Mode tells whether a Recorder sends requests to the API or serves them from its cassette.
+type Mode int
+
+const (
+	// Replay serves the recorded responses and never sends a request.
+	Replay Mode = iota
+	// Record sends the requests and writes the exchanges to the cassette, replacing its content.
+	Record
+	// ReplayOrRecord replays an existing cassette and records a new one when the file does not exist.
+	ReplayOrRecord
+)
+
+// Redacted replaces the values of the redacted headers in a cassette.
+const Redacted = "REDACTED"
+
+// DefaultRedactedHeaders are the headers redacted when Options.RedactHeaders is nil.
+var DefaultRedactedHeaders = []string{"Authorization", "Signature", "Cookie", "Set-Cookie"}
+
+// ErrNoInteraction is returned in replay when the cassette has no unused exchange matching a request.
+// It comes wrapped in an *errors.ErrPermanentFailure, replaying the request again cannot match either.
+var ErrNoInteraction = errors.New("cassette: no recorded interaction matches the request")
+
+// Options configures a Recorder.
+type Options struct {
+	Mode Mode
+	// RedactHeaders are the request and response headers whose values are replaced before being recorded,
+	// DefaultRedactedHeaders when nil.
+	RedactHeaders []string
+	// Next sends the requests in record mode, http.DefaultTransport when nil.
+	Next http.RoundTripper
+}
+
+// Cassette is the content of a cassette file.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded HTTP exchange.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded HTTP request.
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Recorder is an http.RoundTripper recording to or replaying from a cassette file.
+type Recorder struct {
+	path     string
+	mode     Mode
+	redacted []string
+	next     http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// New creates a Recorder for the cassette at path. In replay the cassette is loaded up front.
+func New(path string, opts Options) (*Recorder, error) {
+	mode := opts.Mode
+	if mode == ReplayOrRecord {
+		mode = Replay
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			mode = Record
+		}
+	}
+
+	redacted := opts.RedactHeaders
+	if redacted == nil {
+		redacted = DefaultRedactedHeaders
+	}
+
+	next := opts.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	r := &Recorder{path: path, mode: mode, redacted: redacted, next: next}
+
+	if mode == Replay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("cassette: %w", err)
+		}
+
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("cassette: invalid cassette %s: %w", path, err)
+		}
+
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+
+	return r, nil
+}
+
+// Mode returns the mode the Recorder runs in, Replay or Record.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Cassette returns a copy of the interactions loaded or recorded so far.
+func (r *Recorder) Cassette() Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return Cassette{Interactions: append([]Interaction(nil), r.cassette.Interactions...)}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := Request{
+		Method:  req.Method,
+		URL:     req.URL.String(),
+		Headers: r.redact(req.Header),
+		Body:    string(body),
+	}
+
+	if r.mode == Replay {
+		return r.replay(req, recorded)
+	}
+
+	sent := req.Clone(req.Context())
+	if body != nil {
+		sent.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return r.record(sent, recorded)
+}
+
+func (r *Recorder) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || !matches(interaction.Request, recorded) {
+			continue
+		}
+
+		r.used[i] = true
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Headers.Clone(),
+			Body:          io.NopCloser(bytes.NewBufferString(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, &errors2.ErrPermanentFailure{
+		Detail: fmt.Sprintf("%v: %s %s", ErrNoInteraction, recorded.Method, recorded.URL),
+		Err:    ErrNoInteraction,
+	}
+}
+
+func (r *Recorder) record(req *http.Request, recorded Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, fmt.Errorf("cassette: failed to read response body: %w", err)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Headers:    r.redact(resp.Header),
+			Body:       string(body),
+		},
+	})
+
+	if err := r.save(); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// save writes the cassette, through a temporary file so an interrupted test does not leave it truncated.
+func (r *Recorder) save() error {
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cassette: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("cassette: %w", err)
+	}
+
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("cassette: %w", err)
+	}
+
+	if err := os.Rename(tmp, r.path); err != nil {
+		return fmt.Errorf("cassette: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Recorder) redact(headers http.Header) http.Header {
+	redacted := headers.Clone()
+
+	for _, name := range r.redacted {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, Redacted)
+		}
+	}
+
+	return redacted
+}
+
+// readBody reads and closes the body of a request.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+
+	if err != nil {
+		return nil, fmt.Errorf("cassette: failed to read request body: %w", err)
+	}
+
+	return body, nil
+}
+
+// matches compares the method, path, query and body of two requests. Query parameters match in any
+// order and JSON bodies match regardless of formatting and key order.
+func matches(recorded, req Request) bool {
+	if recorded.Method != req.Method {
+		return false
+	}
+
+	recordedURL, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+
+	reqURL, err := url.Parse(req.URL)
+	if err != nil {
+		return false
+	}
+
+	if recordedURL.Path != reqURL.Path || !reflect.DeepEqual(recordedURL.Query(), reqURL.Query()) {
+		return false
+	}
+
+	return sameBody(recorded.Body, req.Body)
+}
+
+func sameBody(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	var decodedA, decodedB interface{}
+	if json.Unmarshal([]byte(a), &decodedA) != nil || json.Unmarshal([]byte(b), &decodedB) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(decodedA, decodedB)
+}
@@ -0,0 +1,219 @@
+package cassette_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/models"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/transport"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/transport/cassette"
+	transporthttp "github.com/aabri-assignments/form3-accounts/v1/accounts/transport/http"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/utils"
+	"github.com/aabri-assignments/form3-accounts/v1/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAccountServer serves a single account until it is deleted.
+func newAccountServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	deleted := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		w.Header().Set("Set-Cookie", "session=secret")
+
+		switch {
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			io.Copy(w, r.Body) //nolint:errcheck
+		case r.Method == http.MethodDelete:
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		case deleted:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error_message": "record test-id does not exist"}`)
+		case r.URL.Path == "/accounts/":
+			fmt.Fprintf(w, `{"data": [{"id": "test-id", "version": 0}], "meta": {"query": %q}}`, r.URL.RawQuery)
+		default:
+			fmt.Fprint(w, `{"data": {"id": "test-id", "version": 0}}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newTransport(baseURL string, recorder *cassette.Recorder) transport.Transport {
+	return transporthttp.NewWithOptions(baseURL, "/accounts/", transporthttp.Options{
+		RoundTripper: recorder,
+		Middlewares:  []transporthttp.Middleware{transporthttp.StaticHeaders(http.Header{"Authorization": {"Bearer secret"}})},
+	})
+}
+
+// exercise runs the same calls against a recorded or replayed transport.
+func exercise(t *testing.T, accounts transport.Transport) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	created, err := accounts.Create(ctx, &utils.CreateAccountRequest{Data: models.AccountData{ID: "test-id", Type: "accounts"}})
+	require.NoError(t, err)
+	assert.Equal(t, "test-id", created.Data.ID)
+
+	listed, err := accounts.List(ctx, &utils.ListAccountsRequest{PageNumber: 1, PageSize: 10, Filter: utils.AccountFilter{Country: "GB"}})
+	require.NoError(t, err)
+	assert.Len(t, listed.Data, 1)
+	assert.Contains(t, listed.Meta["query"], "filter%5Bcountry%5D=GB")
+
+	fetched, err := accounts.Fetch(ctx, "test-id")
+	require.NoError(t, err)
+	assert.Equal(t, "test-id", fetched.Data.ID)
+
+	require.NoError(t, accounts.Delete(ctx, &utils.DeleteAccountRequest{ID: "test-id", Version: 0}))
+
+	_, err = accounts.Fetch(ctx, "test-id")
+	var notFound *errors.ErrNotFound
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures", "accounts.json")
+	server := newAccountServer(t)
+
+	recorder, err := cassette.New(path, cassette.Options{Mode: cassette.Record})
+	require.NoError(t, err)
+	exercise(t, newTransport(server.URL, recorder))
+	server.Close()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret", "Expected the credentials to be redacted")
+
+	var recorded cassette.Cassette
+	require.NoError(t, json.Unmarshal(data, &recorded))
+	assert.Len(t, recorded.Interactions, 5)
+	assert.Equal(t, cassette.Redacted, recorded.Interactions[0].Request.Headers.Get("Authorization"))
+	assert.Equal(t, cassette.Redacted, recorded.Interactions[0].Response.Headers.Get("Set-Cookie"))
+
+	replayer, err := cassette.New(path, cassette.Options{Mode: cassette.Replay})
+	require.NoError(t, err)
+	exercise(t, newTransport(server.URL, replayer))
+}
+
+func TestReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	fixture := cassette.Cassette{Interactions: []cassette.Interaction{{
+		Request: cassette.Request{
+			Method: http.MethodPost,
+			URL:    "http://localhost/accounts/?page%5Bnumber%5D=1&page%5Bsize%5D=10",
+			Body:   `{"data": {"id": "test-id", "type": "accounts"}}`,
+		},
+		Response: cassette.Response{StatusCode: http.StatusCreated, Body: `{"data": {"id": "test-id"}}`},
+	}}}
+	data, err := json.Marshal(fixture)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	send := func(recorder *cassette.Recorder, url, body string) (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		require.NoError(t, err)
+
+		return recorder.RoundTrip(req)
+	}
+
+	t.Run("Matches the query in any order and JSON bodies regardless of formatting", func(t *testing.T) {
+		recorder, err := cassette.New(path, cassette.Options{})
+		require.NoError(t, err)
+
+		resp, err := send(recorder, "http://other-host/accounts/?page%5Bsize%5D=10&page%5Bnumber%5D=1", `{"data":{"type":"accounts","id":"test-id"}}`)
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.JSONEq(t, `{"data": {"id": "test-id"}}`, string(body))
+	})
+	t.Run("Serves an interaction once", func(t *testing.T) {
+		recorder, err := cassette.New(path, cassette.Options{})
+		require.NoError(t, err)
+
+		_, err = send(recorder, fixture.Interactions[0].Request.URL, fixture.Interactions[0].Request.Body)
+		require.NoError(t, err)
+		_, err = send(recorder, fixture.Interactions[0].Request.URL, fixture.Interactions[0].Request.Body)
+		assert.ErrorIs(t, err, cassette.ErrNoInteraction)
+	})
+	t.Run("Does not match another body", func(t *testing.T) {
+		recorder, err := cassette.New(path, cassette.Options{})
+		require.NoError(t, err)
+
+		_, err = send(recorder, fixture.Interactions[0].Request.URL, `{"data": {"id": "other-id"}}`)
+		assert.ErrorIs(t, err, cassette.ErrNoInteraction)
+	})
+	t.Run("Fails without a cassette", func(t *testing.T) {
+		_, err := cassette.New(filepath.Join(t.TempDir(), "missing.json"), cassette.Options{Mode: cassette.Replay})
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+type countingRoundTripper struct {
+	next  http.RoundTripper
+	calls int
+}
+
+func (c *countingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.calls++
+
+	return c.next.RoundTrip(req)
+}
+
+func TestReplayMissThroughClient(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"interactions": []}`), 0o600))
+
+	recorder, err := cassette.New(path, cassette.Options{Mode: cassette.Replay})
+	require.NoError(t, err)
+	roundTripper := &countingRoundTripper{next: recorder}
+
+	// A retry would wait a minute and outlast the context, the miss must come back after one attempt.
+	client := accounts.New(accounts.Options{
+		BaseURL:      "http://localhost",
+		Retries:      3,
+		InitialDelay: time.Minute,
+		LogLevel:     logging.LevelError,
+		HTTP:         transporthttp.Options{RoundTripper: roundTripper},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.FetchWithContext(ctx, "test-id")
+	assert.ErrorIs(t, err, cassette.ErrNoInteraction)
+	assert.IsType(t, &errors.ErrPermanentFailure{}, err)
+	assert.Equal(t, 1, roundTripper.calls)
+}
+
+func TestReplayOrRecord(t *testing.T) {
+	dir := t.TempDir()
+
+	recorder, err := cassette.New(filepath.Join(dir, "missing.json"), cassette.Options{Mode: cassette.ReplayOrRecord})
+	require.NoError(t, err)
+	assert.Equal(t, cassette.Record, recorder.Mode())
+
+	path := filepath.Join(dir, "existing.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"interactions": []}`), 0o600))
+
+	recorder, err = cassette.New(path, cassette.Options{Mode: cassette.ReplayOrRecord})
+	require.NoError(t, err)
+	assert.Equal(t, cassette.Replay, recorder.Mode())
+}
@@ -0,0 +1,303 @@
+// Package chaos injects faults into the HTTP exchanges of the client, to test retries against realistic
+// failures. A Transport is an http.RoundTripper wrapping the one that sends the requests, it is plugged into
+// the client with the RoundTripper field of http.Options. Faults are drawn from a seeded source, so a test
+// sees the same faults on every run.
+package chaos
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Fault is a kind of failure injected into an exchange.
+type Fault int
+
+const (
+	// Latency delays the request by Rule.Latency before sending it.
+	Latency Fault = iota
+	// Status answers with Rule.StatusCode without sending the request.
+	Status
+	// ConnectionReset fails the request with a connection reset by peer without sending it.
+	ConnectionReset
+	// TruncatedBody sends the request and cuts its response body in half, reading it fails with io.ErrUnexpectedEOF.
+	TruncatedBody
+	// MalformedJSON sends the request and replaces its response body with invalid JSON.
+	MalformedJSON
+)
+
+func (f Fault) String() string {
+	switch f {
+	case Latency:
+		return "latency"
+	case Status:
+		return "status"
+	case ConnectionReset:
+		return "connection reset"
+	case TruncatedBody:
+		return "truncated body"
+	case MalformedJSON:
+		return "malformed JSON"
+	default:
+		return "fault(" + strconv.Itoa(int(f)) + ")"
+	}
+}
+
+// Operation is the account operation a request performs.
+type Operation string
+
+const (
+	Create Operation = "create"
+	Fetch  Operation = "fetch"
+	List   Operation = "list"
+	Update Operation = "update"
+	Delete Operation = "delete"
+	// Other is any request that is not an account operation, such as a health check.
+	Other Operation = "other"
+)
+
+const accountsPath = "/v1/organisation/accounts"
+
+// Rule injects a fault into the requests of its operations with the given probability.
+type Rule struct {
+	Fault Fault
+	// Probability is the chance, from 0 to 1, that a request gets the fault.
+	Probability float64
+	// Operations are the operations the rule applies to, every operation when empty.
+	Operations []Operation
+	// Latency is the delay added by a Latency fault.
+	Latency time.Duration
+	// StatusCode is the status of a Status fault, 503 when zero.
+	StatusCode int
+	// RetryAfter sets the Retry-After header of a Status fault when positive, rounded up to whole seconds.
+	RetryAfter time.Duration
+}
+
+// Options configures a Transport.
+type Options struct {
+	// Seed seeds the source the faults are drawn from.
+	Seed int64
+	// Rules are checked in order for every request. Latency faults add up with the other faults,
+	// the first other fault drawn is the one injected.
+	Rules []Rule
+	// Next sends the requests, http.DefaultTransport when nil.
+	Next http.RoundTripper
+}
+
+// Transport is an http.RoundTripper injecting faults into the requests it sends, safe for concurrent use.
+type Transport struct {
+	rules []Rule
+	next  http.RoundTripper
+
+	mu       sync.Mutex
+	rand     *rand.Rand
+	injected map[Fault]int
+}
+
+// New creates a Transport injecting the faults described by opts.
+func New(opts Options) *Transport {
+	next := opts.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &Transport{
+		rules:    append([]Rule(nil), opts.Rules...),
+		next:     next,
+		rand:     rand.New(rand.NewSource(opts.Seed)), //nolint:gosec
+		injected: make(map[Fault]int),
+	}
+}
+
+// Injected returns how many times each fault was injected.
+func (t *Transport) Injected() map[Fault]int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	injected := make(map[Fault]int, len(t.injected))
+	for fault, count := range t.injected {
+		injected[fault] = count
+	}
+
+	return injected
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	delay, rule := t.draw(OperationOf(req))
+
+	if delay > 0 {
+		if err := sleep(req.Context(), delay); err != nil {
+			closeBody(req)
+
+			return nil, err
+		}
+	}
+
+	if rule == nil {
+		return t.next.RoundTrip(req)
+	}
+
+	switch rule.Fault {
+	case Status:
+		closeBody(req)
+
+		return statusResponse(req, rule), nil
+	case ConnectionReset:
+		closeBody(req)
+
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if rule.Fault == TruncatedBody {
+		resp.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body[:len(body)/2]), errReader{io.ErrUnexpectedEOF}))
+		resp.ContentLength = -1
+	} else {
+		body = append([]byte(`{"data": `), body...)
+		resp.Body = io.NopCloser(bytes.NewReader(body[:len(body)-1]))
+		resp.ContentLength = int64(len(body) - 1)
+	}
+
+	resp.Header.Del("Content-Length")
+
+	return resp, nil
+}
+
+// draw rolls every rule applying to the operation and returns the latency to add and the other fault to inject.
+func (t *Transport) draw(operation Operation) (time.Duration, *Rule) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var (
+		delay time.Duration
+		fault *Rule
+	)
+
+	for i := range t.rules {
+		rule := &t.rules[i]
+		if !rule.appliesTo(operation) || t.rand.Float64() >= rule.Probability {
+			continue
+		}
+
+		switch {
+		case rule.Fault == Latency:
+			delay += rule.Latency
+		case fault == nil:
+			fault = rule
+		default:
+			continue
+		}
+
+		t.injected[rule.Fault]++
+	}
+
+	return delay, fault
+}
+
+func (r *Rule) appliesTo(operation Operation) bool {
+	if len(r.Operations) == 0 {
+		return true
+	}
+
+	for _, o := range r.Operations {
+		if o == operation {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OperationOf returns the account operation a request performs.
+func OperationOf(req *http.Request) Operation {
+	path := strings.TrimSuffix(req.URL.Path, "/")
+
+	switch {
+	case path == accountsPath && req.Method == http.MethodPost:
+		return Create
+	case path == accountsPath && req.Method == http.MethodGet:
+		return List
+	case !strings.HasPrefix(path, accountsPath+"/"):
+		return Other
+	case req.Method == http.MethodGet:
+		return Fetch
+	case req.Method == http.MethodPatch:
+		return Update
+	case req.Method == http.MethodDelete:
+		return Delete
+	default:
+		return Other
+	}
+}
+
+func statusResponse(req *http.Request, rule *Rule) *http.Response {
+	statusCode := rule.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	body := fmt.Sprintf(`{"error_message": "chaos: injected %d %s"}`, statusCode, http.StatusText(statusCode))
+	header := http.Header{"Content-Type": {"application/vnd.api+json"}}
+
+	if rule.RetryAfter > 0 {
+		header.Set("Retry-After", strconv.Itoa(int((rule.RetryAfter+time.Second-1)/time.Second)))
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+type errReader struct {
+	err error
+}
+
+func (e errReader) Read([]byte) (int, error) {
+	return 0, e.err
+}
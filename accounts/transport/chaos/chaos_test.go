@@ -0,0 +1,246 @@
+package chaos_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/models"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/retry"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/transport/chaos"
+	transporthttp "github.com/aabri-assignments/form3-accounts/v1/accounts/transport/http"
+	"github.com/aabri-assignments/form3-accounts/v1/pkg/fakeapi"
+	"github.com/aabri-assignments/form3-accounts/v1/pkg/logging"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const maxRetries = 3
+
+type fixture struct {
+	client *accounts.AccountClient
+	chaos  *chaos.Transport
+	api    *fakeapi.Server
+}
+
+func newFixture(t *testing.T, rules ...chaos.Rule) *fixture {
+	t.Helper()
+
+	api := fakeapi.New(nil)
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	faults := chaos.New(chaos.Options{Seed: 42, Rules: rules})
+
+	return &fixture{
+		client: &accounts.AccountClient{
+			Transport: transporthttp.NewWithOptions(server.URL, "/v1/organisation/accounts/", transporthttp.Options{RoundTripper: faults}),
+			Retry:     retry.NewConstantBackOff(time.Millisecond, maxRetries),
+			Logger:    &logging.Leveled{Level: logging.LevelError},
+		},
+		chaos: faults,
+		api:   api,
+	}
+}
+
+func (f *fixture) seed() models.AccountData {
+	country := "GB"
+	account := models.AccountData{
+		ID:             uuid.NewString(),
+		OrganisationID: uuid.NewString(),
+		Type:           "accounts",
+		Attributes:     &models.AccountAttributes{Country: &country, Name: []string{"Samantha Holder"}},
+	}
+	f.api.Store.Seed(account)
+
+	return account
+}
+
+func TestDeterministicFaults(t *testing.T) {
+	statuses := func(seed int64) []int {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+
+		faults := chaos.New(chaos.Options{Seed: seed, Rules: []chaos.Rule{
+			{Fault: chaos.Status, Probability: 0.3, StatusCode: http.StatusTooManyRequests},
+			{Fault: chaos.Status, Probability: 0.3, StatusCode: http.StatusInternalServerError},
+		}})
+		client := &http.Client{Transport: faults}
+
+		var statuses []int
+
+		for i := 0; i < 50; i++ {
+			resp, err := client.Get(server.URL + "/v1/organisation/accounts")
+			require.NoError(t, err)
+			resp.Body.Close()
+			statuses = append(statuses, resp.StatusCode)
+		}
+
+		return statuses
+	}
+
+	assert.Equal(t, statuses(7), statuses(7))
+	assert.NotEqual(t, statuses(7), statuses(8))
+	assert.Contains(t, statuses(7), http.StatusTooManyRequests)
+	assert.Contains(t, statuses(7), http.StatusInternalServerError)
+	assert.Contains(t, statuses(7), http.StatusOK)
+}
+
+func TestStatusFaults(t *testing.T) {
+	t.Run("Retries until the API answers", func(t *testing.T) {
+		f := newFixture(t, chaos.Rule{Fault: chaos.Status, Probability: 0.5, Operations: []chaos.Operation{chaos.Fetch}})
+		f.client.Retry = retry.NewConstantBackOff(time.Millisecond, 10)
+		account := f.seed()
+
+		for i := 0; i < 10; i++ {
+			fetched, err := f.client.Fetch(account.ID)
+			require.NoError(t, err)
+			assert.Equal(t, account.ID, fetched.ID)
+		}
+
+		assert.Positive(t, f.chaos.Injected()[chaos.Status])
+	})
+
+	for _, statusCode := range []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable} {
+		t.Run(http.StatusText(statusCode)+" is retried until the retries run out", func(t *testing.T) {
+			f := newFixture(t, chaos.Rule{Fault: chaos.Status, Probability: 1, StatusCode: statusCode})
+			account := f.seed()
+
+			_, err := f.client.Fetch(account.ID)
+
+			var apiErr *errors.APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, statusCode, apiErr.StatusCode)
+			assert.Equal(t, maxRetries+1, f.chaos.Injected()[chaos.Status])
+		})
+	}
+
+	t.Run("Sets Retry-After", func(t *testing.T) {
+		faults := chaos.New(chaos.Options{Rules: []chaos.Rule{
+			{Fault: chaos.Status, Probability: 1, StatusCode: http.StatusTooManyRequests, RetryAfter: 1500 * time.Millisecond},
+		}})
+
+		req := httptest.NewRequest(http.MethodGet, "http://localhost/v1/health", nil)
+		resp, err := faults.RoundTrip(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, "2", resp.Header.Get("Retry-After"))
+	})
+
+	t.Run("Waits for Retry-After up to MaxRetryAfter", func(t *testing.T) {
+		f := newFixture(t, chaos.Rule{Fault: chaos.Status, Probability: 1, StatusCode: http.StatusTooManyRequests, RetryAfter: time.Second})
+		f.client.MaxRetryAfter = 20 * time.Millisecond
+		account := f.seed()
+
+		start := time.Now()
+		_, err := f.client.Fetch(account.ID)
+
+		var apiErr *errors.APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, time.Second, apiErr.RetryAfter)
+		assert.GreaterOrEqual(t, time.Since(start), maxRetries*f.client.MaxRetryAfter)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
+
+func TestConnectionReset(t *testing.T) {
+	t.Run("Retries until the connection holds", func(t *testing.T) {
+		f := newFixture(t, chaos.Rule{Fault: chaos.ConnectionReset, Probability: 0.5})
+		f.client.Retry = retry.NewConstantBackOff(time.Millisecond, 10)
+		account := f.seed()
+
+		for i := 0; i < 10; i++ {
+			_, err := f.client.Fetch(account.ID)
+			require.NoError(t, err)
+		}
+
+		assert.Positive(t, f.chaos.Injected()[chaos.ConnectionReset])
+	})
+
+	t.Run("Fails with a network error once the retries run out", func(t *testing.T) {
+		f := newFixture(t, chaos.Rule{Fault: chaos.ConnectionReset, Probability: 1})
+		account := f.seed()
+
+		_, err := f.client.Fetch(account.ID)
+
+		var network *errors.ErrNetwork
+		assert.ErrorAs(t, err, &network)
+		assert.ErrorIs(t, err, syscall.ECONNRESET)
+		assert.Equal(t, maxRetries+1, f.chaos.Injected()[chaos.ConnectionReset])
+	})
+}
+
+func TestBodyFaults(t *testing.T) {
+	for _, fault := range []chaos.Fault{chaos.TruncatedBody, chaos.MalformedJSON} {
+		t.Run(fault.String(), func(t *testing.T) {
+			f := newFixture(t, chaos.Rule{Fault: fault, Probability: 1})
+			account := f.seed()
+
+			_, err := f.client.Fetch(account.ID)
+
+			var permanent *errors.ErrPermanentFailure
+			assert.ErrorAs(t, err, &permanent)
+			assert.Equal(t, 1, f.chaos.Injected()[fault], "Expected an undecodable response not to be retried")
+		})
+	}
+
+	t.Run("Truncated bodies fail to read", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"data": {"id": "test-id"}}`)) //nolint:errcheck
+		}))
+		defer server.Close()
+
+		client := &http.Client{Transport: chaos.New(chaos.Options{Rules: []chaos.Rule{{Fault: chaos.TruncatedBody, Probability: 1}}})}
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		assert.Equal(t, `{"data": {"id`, string(body))
+	})
+}
+
+func TestOperations(t *testing.T) {
+	f := newFixture(t, chaos.Rule{Fault: chaos.Status, Probability: 1, StatusCode: http.StatusInternalServerError, Operations: []chaos.Operation{chaos.Delete}})
+	account := f.seed()
+
+	_, err := f.client.Fetch(account.ID)
+	assert.NoError(t, err)
+	assert.Error(t, f.client.Delete(account.ID, 0))
+
+	_, ok := f.api.Store.Account(account.ID)
+	assert.True(t, ok, "Expected the failed delete not to reach the API")
+
+	for req, operation := range map[*http.Request]chaos.Operation{
+		httptest.NewRequest(http.MethodPost, "/v1/organisation/accounts", nil):      chaos.Create,
+		httptest.NewRequest(http.MethodGet, "/v1/organisation/accounts/", nil):      chaos.List,
+		httptest.NewRequest(http.MethodGet, "/v1/organisation/accounts/id", nil):    chaos.Fetch,
+		httptest.NewRequest(http.MethodPatch, "/v1/organisation/accounts/id", nil):  chaos.Update,
+		httptest.NewRequest(http.MethodDelete, "/v1/organisation/accounts/id", nil): chaos.Delete,
+		httptest.NewRequest(http.MethodGet, "/v1/health", nil):                      chaos.Other,
+	} {
+		assert.Equal(t, operation, chaos.OperationOf(req), "%s %s", req.Method, req.URL.Path)
+	}
+}
+
+func TestLatency(t *testing.T) {
+	f := newFixture(t, chaos.Rule{Fault: chaos.Latency, Probability: 1, Latency: time.Second})
+	account := f.seed()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := f.client.FetchWithContext(ctx, account.ID)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
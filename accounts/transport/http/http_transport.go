@@ -2,8 +2,12 @@ package http
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	errors2 "github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
@@ -18,13 +22,23 @@ type Transport struct {
 }
 
 func New(baseURL, basePath string) transport.Transport {
+	return NewWithOptions(baseURL, basePath, Options{})
+}
+
+// NewWithOptions creates a transport that sends its requests with the HTTP client described by opts.
+func NewWithOptions(baseURL, basePath string, opts Options) transport.Transport {
 	return &Transport{
-		httpClient: &http.Client{},
+		httpClient: NewHTTPClient(opts),
 		BaseURL:    baseURL,
 		BasePath:   basePath,
 	}
 }
 
+// HTTPClient returns the HTTP client the transport sends its requests with.
+func (t *Transport) HTTPClient() *http.Client {
+	return t.httpClient
+}
+
 func (t *Transport) Create(context context.Context, req *utils.CreateAccountRequest) (*utils.CreateAccountResponse, error) {
 	url := t.BaseURL + t.BasePath
 
@@ -42,7 +56,7 @@ func (t *Transport) Create(context context.Context, req *utils.CreateAccountRequ
 
 	resp, err := t.httpClient.Do(httpReq)
 	if err != nil {
-		return nil, &errors2.ErrPermanentFailure{Detail: fmt.Sprintf("failed to send HTTP request: %v", err)}
+		return nil, sendError(context, err)
 	}
 	defer resp.Body.Close()
 
@@ -68,7 +82,7 @@ func (t *Transport) Fetch(context context.Context, accountID string) (*utils.Fet
 
 	resp, err := t.httpClient.Do(httpReq)
 	if err != nil {
-		return nil, &errors2.ErrPermanentFailure{Detail: fmt.Sprintf("failed to send HTTP request: %v", err)}
+		return nil, sendError(context, err)
 	}
 	defer resp.Body.Close()
 
@@ -94,7 +108,7 @@ func (t *Transport) Delete(context context.Context, req *utils.DeleteAccountRequ
 
 	resp, err := t.httpClient.Do(httpReq)
 	if err != nil {
-		return &errors2.ErrPermanentFailure{Detail: fmt.Sprintf("failed to send HTTP request: %v", err)}
+		return sendError(context, err)
 	}
 	defer resp.Body.Close()
 
@@ -104,3 +118,116 @@ func (t *Transport) Delete(context context.Context, req *utils.DeleteAccountRequ
 
 	return nil
 }
+
+func (t *Transport) Update(context context.Context, req *utils.UpdateAccountRequest) (*utils.UpdateAccountResponse, error) {
+	url := t.BaseURL + t.BasePath + req.Data.ID
+
+	body, err := utils.EncodeJSONRequest(req)
+	if err != nil {
+		return nil, &errors2.ErrBadRequest{Detail: "failed to marshal request body"}
+	}
+
+	httpReq, err := http.NewRequestWithContext(context, http.MethodPatch, url, body)
+	if err != nil {
+		return nil, &errors2.ErrBadRequest{Detail: "failed to create HTTP request"}
+	}
+
+	httpReq.Header.Set("Content-Type", "application/vnd.api+json")
+
+	resp, err := t.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, sendError(context, err)
+	}
+	defer resp.Body.Close()
+
+	if err := errors2.HandleHTTPError(resp); err != nil {
+		return nil, err
+	}
+
+	var updateResp utils.UpdateAccountResponse
+	if err := utils.DecodeJSONResponse(resp, &updateResp); err != nil {
+		return nil, &errors2.ErrPermanentFailure{Detail: "failed to unmarshal response body"}
+	}
+
+	return &updateResp, nil
+}
+
+func (t *Transport) List(context context.Context, req *utils.ListAccountsRequest) (*utils.ListAccountsResponse, error) {
+	query := url.Values{}
+	if req.PageNumber > 0 {
+		query.Set("page[number]", strconv.Itoa(req.PageNumber))
+	}
+
+	if req.PageSize > 0 {
+		query.Set("page[size]", strconv.Itoa(req.PageSize))
+	}
+
+	for attribute, value := range req.Filter.Params() {
+		query.Set("filter["+attribute+"]", value)
+	}
+
+	url := t.BaseURL + t.BasePath
+	if len(query) > 0 {
+		url += "?" + query.Encode()
+	}
+
+	httpReq, err := http.NewRequestWithContext(context, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, &errors2.ErrBadRequest{Detail: "failed to create HTTP request"}
+	}
+
+	resp, err := t.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, sendError(context, err)
+	}
+	defer resp.Body.Close()
+
+	if err := errors2.HandleHTTPError(resp); err != nil {
+		return nil, err
+	}
+
+	var listResp utils.ListAccountsResponse
+	if err := utils.DecodeJSONResponse(resp, &listResp); err != nil {
+		return nil, &errors2.ErrPermanentFailure{Detail: "failed to unmarshal response body"}
+	}
+
+	return &listResp, nil
+}
+
+// sendError converts an error returned by the HTTP client into a library error.
+// Cancellation and deadline errors are wrapped so they can be matched with errors.Is, rate limiter and
+// configuration errors are returned as they are, and certificate errors are permanent failures.
+// Any other error is an *errors.ErrNetwork, which is retried.
+func sendError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("failed to send HTTP request: %w", ctxErr)
+	}
+
+	var rateLimited *errors2.ErrRateLimited
+	if errors.As(err, &rateLimited) {
+		return rateLimited
+	}
+
+	var permanent *errors2.ErrPermanentFailure
+	if errors.As(err, &permanent) {
+		return permanent
+	}
+
+	if isCertificateError(err) {
+		return &errors2.ErrPermanentFailure{Detail: fmt.Sprintf("failed to send HTTP request: %v", err)}
+	}
+
+	return &errors2.ErrNetwork{Err: err}
+}
+
+func isCertificateError(err error) bool {
+	var (
+		verification       *tls.CertificateVerificationError
+		unknownAuthority   x509.UnknownAuthorityError
+		hostname           x509.HostnameError
+		invalidCertificate x509.CertificateInvalidError
+	)
+
+	return errors.As(err, &verification) || errors.As(err, &unknownAuthority) ||
+		errors.As(err, &hostname) || errors.As(err, &invalidCertificate)
+}
@@ -1,7 +1,9 @@
 package http_test
 
 import (
+	errors2 "errors"
 	"fmt"
+	"io"
 	http2 "net/http"
 	"net/http/httptest"
 	"testing"
@@ -25,6 +27,9 @@ func TestHttpTransport(t *testing.T) {
 	t.Run("TestCreateWithError", suite.TestCreateWithError)
 	t.Run("TestFetch", suite.TestFetch)
 	t.Run("TestDelete", suite.TestDelete)
+	t.Run("TestCancelledContext", suite.TestCancelledContext)
+	t.Run("TestList", suite.TestList)
+	t.Run("TestUpdate", suite.TestUpdate)
 }
 func (suite *HttpTestSuite) TestCreate(t *testing.T) {
 	server := createMockServer()
@@ -115,6 +120,82 @@ func (suite *HttpTestSuite) TestDelete(t *testing.T) {
 	err := transport.Delete(suite.ctx, req)
 	assert.NoError(t, err)
 }
+func (suite *HttpTestSuite) TestCancelledContext(t *testing.T) {
+	server := createMockServer()
+	defer server.Close()
+
+	transport := http.New(server.URL, "/fetch/")
+	ctx, cancel := context.WithCancel(suite.ctx)
+	cancel()
+
+	_, err := transport.Fetch(ctx, "test-id")
+	assert.Error(t, err)
+	assert.True(t, errors2.Is(err, context.Canceled))
+}
+func (suite *HttpTestSuite) TestList(t *testing.T) {
+	var query string
+
+	server := httptest.NewServer(http2.HandlerFunc(func(w http2.ResponseWriter, r *http2.Request) {
+		query = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		fmt.Fprint(w, `{"data": [{"id": "test-id", "type": "accounts"}], "links": {"next": "/list/?page%5Bnumber%5D=3"}}`)
+	}))
+	defer server.Close()
+
+	transport := http.New(server.URL, "/list/")
+	resp, err := transport.List(suite.ctx, &utils.ListAccountsRequest{
+		PageNumber: 2,
+		PageSize:   1,
+		Filter:     utils.AccountFilter{Country: "GB", BankID: "400300"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "filter%5Bbank_id%5D=400300&filter%5Bcountry%5D=GB&page%5Bnumber%5D=2&page%5Bsize%5D=1", query)
+	assert.Len(t, resp.Data, 1)
+	assert.Equal(t, "test-id", resp.Data[0].ID)
+	assert.Equal(t, "/list/?page%5Bnumber%5D=3", resp.Links.Next)
+}
+func (suite *HttpTestSuite) TestUpdate(t *testing.T) {
+	var method, body string
+
+	server := httptest.NewServer(http2.HandlerFunc(func(w http2.ResponseWriter, r *http2.Request) {
+		method = r.Method
+		raw, _ := io.ReadAll(r.Body)
+		body = string(raw)
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		if r.URL.Path != "/update/test-id" {
+			w.WriteHeader(http2.StatusConflict)
+			fmt.Fprint(w, `{"message": "invalid version"}`)
+			return
+		}
+		fmt.Fprint(w, `{"data": {"id": "test-id", "type": "accounts", "version": 1, "attributes": {"name": ["Jane Smith"]}}}`)
+	}))
+	defer server.Close()
+
+	transport := http.New(server.URL, "/update/")
+	req := &utils.UpdateAccountRequest{
+		Data: utils.UpdateAccountData{
+			ID:         "test-id",
+			Type:       "accounts",
+			Version:    0,
+			Attributes: models.AccountAttributesUpdate{Name: []string{"Jane Smith"}},
+		},
+	}
+
+	resp, err := transport.Update(suite.ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, http2.MethodPatch, method)
+	assert.JSONEq(t, `{"data": {"id": "test-id", "type": "accounts", "version": 0, "attributes": {"name": ["Jane Smith"]}}}`, body)
+	assert.Equal(t, int64(1), *resp.Data.Version)
+
+	req.Data.Attributes = models.AccountAttributesUpdate{AlternativeNames: &[]string{}}
+	_, err = transport.Update(suite.ctx, req)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"data": {"id": "test-id", "type": "accounts", "version": 0, "attributes": {"alternative_names": []}}}`, body)
+
+	req.Data.ID = "other-id"
+	_, err = transport.Update(suite.ctx, req)
+	assert.IsType(t, &errors.ErrConflict{}, err)
+}
 
 func createMockServer() *httptest.Server {
 	server := httptest.NewServer(http2.HandlerFunc(func(w http2.ResponseWriter, r *http2.Request) {
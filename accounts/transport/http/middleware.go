@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// RequestIDHeader is the header the RequestID middleware sets.
+	RequestIDHeader = "X-Request-ID"
+	// DefaultUserAgent identifies the library in the User-Agent header.
+	DefaultUserAgent = "form3-accounts/v1"
+)
+
+// Middleware wraps a RoundTripper to act on every request sent by the transport and on its response.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to the http.RoundTripper interface.
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Chain wraps next with the middlewares. The first middleware is the outermost one:
+// it sees a request first and its response last.
+func Chain(next http.RoundTripper, middlewares ...Middleware) http.RoundTripper {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		next = middlewares[i](next)
+	}
+
+	return next
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context whose requests are sent with the given ID by the RequestID middleware.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID set by WithRequestID.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+
+	return id, ok && id != ""
+}
+
+// RequestID sets the X-Request-ID header of requests that do not have one,
+// to the ID of their context when set with WithRequestID and to a new UUID otherwise.
+func RequestID() Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(RequestIDHeader) != "" {
+				return next.RoundTrip(req)
+			}
+
+			id, ok := RequestIDFromContext(req.Context())
+			if !ok {
+				id = uuid.NewString()
+			}
+
+			req = req.Clone(req.Context())
+			req.Header.Set(RequestIDHeader, id)
+
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// UserAgent sets the User-Agent header of every request, DefaultUserAgent when userAgent is empty.
+func UserAgent(userAgent string) Middleware {
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+
+	return StaticHeaders(http.Header{"User-Agent": {userAgent}})
+}
+
+// StaticHeaders sets the given headers on every request, replacing the values already set.
+func StaticHeaders(headers http.Header) Middleware {
+	headers = headers.Clone()
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			for name, values := range headers {
+				req.Header[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+			}
+
+			return next.RoundTrip(req)
+		})
+	}
+}
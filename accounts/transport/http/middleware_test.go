@@ -0,0 +1,129 @@
+package http_test
+
+import (
+	"fmt"
+	http2 "net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/transport/http"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+type recordingRoundTripper struct {
+	requests []*http2.Request
+}
+
+func (r *recordingRoundTripper) RoundTrip(req *http2.Request) (*http2.Response, error) {
+	r.requests = append(r.requests, req)
+
+	return &http2.Response{StatusCode: http2.StatusOK, Body: http2.NoBody, Request: req}, nil
+}
+
+func newRequest(t *testing.T, ctx context.Context) *http2.Request {
+	t.Helper()
+
+	req, err := http2.NewRequestWithContext(ctx, http2.MethodGet, "http://localhost/v1/organisation/accounts", nil)
+	require.NoError(t, err)
+
+	return req
+}
+
+func TestChain(t *testing.T) {
+	var order []string
+
+	trace := func(name string) http.Middleware {
+		return func(next http2.RoundTripper) http2.RoundTripper {
+			return http.RoundTripperFunc(func(req *http2.Request) (*http2.Response, error) {
+				order = append(order, name+" request")
+				resp, err := next.RoundTrip(req)
+				order = append(order, name+" response")
+
+				return resp, err
+			})
+		}
+	}
+
+	recorder := &recordingRoundTripper{}
+	_, err := http.Chain(recorder, trace("first"), trace("second")).RoundTrip(newRequest(t, context.Background()))
+
+	assert.NoError(t, err)
+	assert.Len(t, recorder.requests, 1)
+	assert.Equal(t, []string{"first request", "second request", "second response", "first response"}, order)
+}
+
+func TestRequestID(t *testing.T) {
+	t.Run("Generates an ID", func(t *testing.T) {
+		recorder := &recordingRoundTripper{}
+		req := newRequest(t, context.Background())
+
+		_, err := http.Chain(recorder, http.RequestID()).RoundTrip(req)
+		assert.NoError(t, err)
+
+		_, err = uuid.Parse(recorder.requests[0].Header.Get(http.RequestIDHeader))
+		assert.NoError(t, err)
+		assert.Empty(t, req.Header.Get(http.RequestIDHeader), "Expected the original request to be left unchanged")
+	})
+	t.Run("Uses the ID of the context", func(t *testing.T) {
+		recorder := &recordingRoundTripper{}
+		req := newRequest(t, http.WithRequestID(context.Background(), "correlation-id"))
+
+		_, err := http.Chain(recorder, http.RequestID()).RoundTrip(req)
+		assert.NoError(t, err)
+		assert.Equal(t, "correlation-id", recorder.requests[0].Header.Get(http.RequestIDHeader))
+	})
+	t.Run("Keeps an ID already set", func(t *testing.T) {
+		recorder := &recordingRoundTripper{}
+		req := newRequest(t, http.WithRequestID(context.Background(), "correlation-id"))
+		req.Header.Set(http.RequestIDHeader, "explicit-id")
+
+		_, err := http.Chain(recorder, http.RequestID()).RoundTrip(req)
+		assert.NoError(t, err)
+		assert.Equal(t, "explicit-id", recorder.requests[0].Header.Get(http.RequestIDHeader))
+	})
+}
+
+func TestHeaderMiddlewares(t *testing.T) {
+	t.Run("Sets the default User-Agent", func(t *testing.T) {
+		recorder := &recordingRoundTripper{}
+		_, err := http.Chain(recorder, http.UserAgent("")).RoundTrip(newRequest(t, context.Background()))
+		assert.NoError(t, err)
+		assert.Equal(t, http.DefaultUserAgent, recorder.requests[0].Header.Get("User-Agent"))
+	})
+	t.Run("Sets static headers", func(t *testing.T) {
+		headers := http2.Header{}
+		headers.Set("X-Team", "payments")
+		headers.Add("x-tags", "a")
+		headers.Add("x-tags", "b")
+
+		recorder := &recordingRoundTripper{}
+		req := newRequest(t, context.Background())
+		req.Header.Set("X-Team", "accounts")
+
+		_, err := http.Chain(recorder, http.StaticHeaders(headers)).RoundTrip(req)
+		assert.NoError(t, err)
+		assert.Equal(t, "payments", recorder.requests[0].Header.Get("X-Team"))
+		assert.Equal(t, []string{"a", "b"}, recorder.requests[0].Header.Values("X-Tags"))
+		assert.Equal(t, "accounts", req.Header.Get("X-Team"))
+	})
+}
+
+func TestOptionsMiddlewares(t *testing.T) {
+	server := httptest.NewServer(http2.HandlerFunc(func(w http2.ResponseWriter, r *http2.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		fmt.Fprintf(w, `{"data": {"id": %q, "type": %q}}`, r.Header.Get(http.RequestIDHeader), r.Header.Get("User-Agent"))
+	}))
+	defer server.Close()
+
+	transport := http.NewWithOptions(server.URL, "/accounts/", http.Options{
+		Middlewares: []http.Middleware{http.RequestID(), http.UserAgent("accounts-service/2.1")},
+	})
+
+	resp, err := transport.Fetch(http.WithRequestID(context.Background(), "correlation-id"), "test-id")
+	assert.NoError(t, err)
+	assert.Equal(t, "correlation-id", resp.Data.ID)
+	assert.Equal(t, "accounts-service/2.1", resp.Data.Type)
+}
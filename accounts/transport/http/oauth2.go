@@ -0,0 +1,241 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultExpiryDelta  = 10 * time.Second
+	defaultTokenTimeout = 30 * time.Second
+)
+
+// Token is an OAuth2 access token.
+type Token struct {
+	AccessToken string
+	TokenType   string
+	Expiry      time.Time
+}
+
+// TokenSource provides the access tokens sent with every request.
+type TokenSource interface {
+	// Token returns a valid token, fetching a new one when needed.
+	Token(ctx context.Context) (*Token, error)
+	// Invalidate discards the token when the API rejected it, so the next call to Token fetches a new one.
+	Invalidate(token *Token)
+}
+
+// ClientCredentials is a TokenSource using the OAuth2 client credentials grant. Tokens are cached until
+// shortly before they expire and concurrent callers share a single request to the token endpoint.
+type ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// HTTPClient sends the token requests, a client with a Timeout of TokenTimeout when nil.
+	HTTPClient *http.Client
+	// ExpiryDelta is how long before their expiry tokens are refreshed, 10 seconds when zero.
+	ExpiryDelta time.Duration
+	// TokenTimeout bounds every request to the token endpoint, 30 seconds when zero. A hanging endpoint
+	// fails the callers waiting for the token instead of blocking them.
+	TokenTimeout time.Duration
+
+	mu       sync.Mutex
+	token    *Token
+	inflight *tokenCall
+}
+
+type tokenCall struct {
+	done  chan struct{}
+	token *Token
+	err   error
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// Token returns the cached token, or fetches a new one when it is missing or about to expire.
+func (c *ClientCredentials) Token(ctx context.Context) (*Token, error) {
+	c.mu.Lock()
+
+	if c.token != nil && c.valid(c.token) {
+		token := c.token
+		c.mu.Unlock()
+
+		return token, nil
+	}
+
+	call := c.inflight
+	if call == nil {
+		call = &tokenCall{done: make(chan struct{})}
+		c.inflight = call
+
+		// The token is fetched independently of the caller's context, so a cancelled caller
+		// does not fail the other callers waiting for the same token.
+		go c.fetch(call)
+	}
+
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.token, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Invalidate discards the cached token if it is still the given one.
+func (c *ClientCredentials) Invalidate(token *Token) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token == token {
+		c.token = nil
+	}
+}
+
+func (c *ClientCredentials) valid(token *Token) bool {
+	expiryDelta := c.ExpiryDelta
+	if expiryDelta == 0 {
+		expiryDelta = defaultExpiryDelta
+	}
+
+	return token.Expiry.IsZero() || time.Now().Add(expiryDelta).Before(token.Expiry)
+}
+
+func (c *ClientCredentials) tokenTimeout() time.Duration {
+	if c.TokenTimeout <= 0 {
+		return defaultTokenTimeout
+	}
+
+	return c.TokenTimeout
+}
+
+func (c *ClientCredentials) fetch(call *tokenCall) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.tokenTimeout())
+	defer cancel()
+
+	call.token, call.err = c.requestToken(ctx)
+
+	c.mu.Lock()
+	if call.err == nil {
+		c.token = call.token
+	}
+	c.inflight = nil
+	c.mu.Unlock()
+
+	close(call.done)
+}
+
+func (c *ClientCredentials) requestToken(ctx context.Context) (*Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(c.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: failed to create token request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: c.tokenTimeout()}
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: failed to send token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+
+		return nil, fmt.Errorf("oauth2: token endpoint returned %d: %s", resp.StatusCode, body)
+	}
+
+	var tokenResp tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return nil, fmt.Errorf("oauth2: failed to unmarshal token response: %w", err)
+	}
+
+	if tokenResp.AccessToken == "" {
+		return nil, fmt.Errorf("oauth2: token response has no access_token")
+	}
+
+	token := &Token{AccessToken: tokenResp.AccessToken, TokenType: tokenResp.TokenType}
+	if tokenResp.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	}
+
+	return token, nil
+}
+
+// BearerRoundTripper sends a bearer token with every request. When the API answers 401 Unauthorized,
+// the token is refreshed and the request is sent once more.
+type BearerRoundTripper struct {
+	Source TokenSource
+	Next   http.RoundTripper
+}
+
+func (b *BearerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := b.Source.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := b.Next.RoundTrip(withBearer(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	b.Source.Invalidate(token)
+
+	token, err = b.Source.Token(req.Context())
+	if err != nil {
+		return resp, nil //nolint:nilerr
+	}
+
+	retry := withBearer(req, token)
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return resp, nil //nolint:nilerr
+		}
+	}
+
+	io.Copy(io.Discard, resp.Body) //nolint:errcheck
+	resp.Body.Close()
+
+	return b.Next.RoundTrip(retry)
+}
+
+func withBearer(req *http.Request, token *Token) *http.Request {
+	tokenType := token.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+
+	authorized := req.Clone(req.Context())
+	authorized.Header.Set("Authorization", tokenType+" "+token.AccessToken)
+
+	return authorized
+}
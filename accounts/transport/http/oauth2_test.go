@@ -0,0 +1,191 @@
+package http_test
+
+import (
+	"fmt"
+	http2 "net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/models"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/transport/http"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+type tokenServer struct {
+	*httptest.Server
+	requests  int32
+	expiresIn int
+}
+
+func newTokenServer(t *testing.T, expiresIn int) *tokenServer {
+	t.Helper()
+
+	server := &tokenServer{expiresIn: expiresIn}
+	server.Server = httptest.NewServer(http2.HandlerFunc(func(w http2.ResponseWriter, r *http2.Request) {
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "client-id" || clientSecret != "client-secret" || r.FormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http2.StatusUnauthorized)
+			fmt.Fprint(w, `{"error": "invalid_client"}`)
+
+			return
+		}
+
+		n := atomic.AddInt32(&server.requests, 1)
+		time.Sleep(10 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "bearer", "expires_in": %d}`, n, server.expiresIn)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func (s *tokenServer) source() *http.ClientCredentials {
+	return &http.ClientCredentials{
+		TokenURL:     s.URL,
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		Scopes:       []string{"accounts"},
+	}
+}
+
+func TestClientCredentials(t *testing.T) {
+	t.Run("Caches the token", func(t *testing.T) {
+		server := newTokenServer(t, 3600)
+		source := server.source()
+
+		first, err := source.Token(context.Background())
+		require.NoError(t, err)
+		second, err := source.Token(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, "token-1", first.AccessToken)
+		assert.Same(t, first, second)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&server.requests))
+	})
+	t.Run("Fetches a single token for concurrent callers", func(t *testing.T) {
+		server := newTokenServer(t, 3600)
+		source := server.source()
+
+		var wg sync.WaitGroup
+		tokens := make([]string, 20)
+
+		for i := range tokens {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				token, err := source.Token(context.Background())
+				if assert.NoError(t, err) {
+					tokens[i] = token.AccessToken
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		for _, token := range tokens {
+			assert.Equal(t, "token-1", token)
+		}
+		assert.Equal(t, int32(1), atomic.LoadInt32(&server.requests))
+	})
+	t.Run("Refreshes the token shortly before it expires", func(t *testing.T) {
+		server := newTokenServer(t, 5)
+		source := server.source()
+		source.ExpiryDelta = 10 * time.Second
+
+		first, err := source.Token(context.Background())
+		require.NoError(t, err)
+		second, err := source.Token(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, "token-1", first.AccessToken)
+		assert.Equal(t, "token-2", second.AccessToken)
+	})
+	t.Run("Invalidate discards the token", func(t *testing.T) {
+		server := newTokenServer(t, 3600)
+		source := server.source()
+
+		first, err := source.Token(context.Background())
+		require.NoError(t, err)
+		source.Invalidate(first)
+		second, err := source.Token(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, "token-2", second.AccessToken)
+	})
+	t.Run("Returns token endpoint errors", func(t *testing.T) {
+		server := newTokenServer(t, 3600)
+		source := server.source()
+		source.ClientSecret = "wrong"
+
+		_, err := source.Token(context.Background())
+		assert.ErrorContains(t, err, "401")
+	})
+	t.Run("Stops waiting when the context is done", func(t *testing.T) {
+		server := newTokenServer(t, 3600)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := server.source().Token(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+	t.Run("Gives up on a token endpoint that hangs", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http2.HandlerFunc(func(w http2.ResponseWriter, r *http2.Request) {
+			<-release
+		}))
+		defer server.Close()
+		defer close(release)
+
+		source := &http.ClientCredentials{TokenURL: server.URL, ClientID: "client-id", TokenTimeout: 50 * time.Millisecond}
+
+		start := time.Now()
+		_, err := source.Token(context.Background())
+		assert.ErrorContains(t, err, "failed to send token request")
+		assert.Less(t, time.Since(start), time.Second)
+
+		// The failed fetch is not left in flight, the next caller sends a new request.
+		_, err = source.Token(context.Background())
+		assert.Error(t, err)
+	})
+}
+
+func TestBearerRoundTripper(t *testing.T) {
+	tokens := newTokenServer(t, 3600)
+
+	var authorizations []string
+	var mu sync.Mutex
+
+	api := httptest.NewServer(http2.HandlerFunc(func(w http2.ResponseWriter, r *http2.Request) {
+		mu.Lock()
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		mu.Unlock()
+
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			w.WriteHeader(http2.StatusUnauthorized)
+			fmt.Fprint(w, `{"message": "token expired"}`)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		fmt.Fprint(w, `{"data": {"id": "test-id"}}`)
+	}))
+	defer api.Close()
+
+	transport := http.NewWithOptions(api.URL, "/accounts/", http.Options{TokenSource: tokens.source()})
+
+	resp, err := transport.Create(context.Background(), &utils.CreateAccountRequest{Data: models.AccountData{ID: "test-id"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "test-id", resp.Data.ID)
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, authorizations)
+
+	_, err = transport.Fetch(context.Background(), "test-id")
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer token-2", authorizations[2])
+}
@@ -0,0 +1,169 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultDialTimeout = 30 * time.Second
+	defaultKeepAlive   = 30 * time.Second
+)
+
+// Options configures the HTTP client used by the transport.
+// HTTPClient takes precedence over the timeout and connection settings, RoundTripper over the connection settings.
+type Options struct {
+	// HTTPClient is used in place of a client built from the settings, its transport is still wrapped
+	// for authentication and by the middlewares.
+	HTTPClient *http.Client
+	// RoundTripper sends the requests when set, in place of a transport built from the connection settings.
+	RoundTripper http.RoundTripper
+	// AttemptTimeout bounds a single HTTP request, a retried operation can take longer.
+	AttemptTimeout time.Duration
+	// MaxIdleConns bounds the idle connections kept across all hosts, zero keeps the net/http default.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost bounds the idle connections kept per host, zero means http.DefaultMaxIdleConnsPerHost.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout is how long an idle connection is kept before it is closed, zero keeps the net/http default.
+	IdleConnTimeout time.Duration
+	// KeepAlive is the TCP keep-alive period of the connections, zero keeps the net/http default
+	// and a negative value disables TCP keep-alives.
+	KeepAlive time.Duration
+	// DisableKeepAlives opens a new connection for every request.
+	DisableKeepAlives bool
+	// TLS configures a custom CA, a client certificate for mutual TLS, the minimum TLS version and certificate pins.
+	// It also applies to an *http.Transport set as RoundTripper or as the transport of HTTPClient, which is
+	// cloned first, while any other RoundTripper rejects it. A configuration that cannot be loaded or applied
+	// makes every request fail, ValidateTLS reports the error up front.
+	TLS *TLSOptions
+	// Signer signs every request with an HTTP signature when set.
+	Signer *Signer
+	// TokenSource provides a bearer token sent with every request when set. With a Signer as well,
+	// the signature goes in the Authorization header only when Signer.SignatureHeader is false.
+	TokenSource TokenSource
+	// RateLimiter holds requests back to stay within a request rate and the rate limits reported by the API.
+	// It can be shared by several clients to limit them together.
+	RateLimiter *RateLimiter
+	// FailOnRateLimit fails requests held back by RateLimiter with an *errors.ErrRateLimited instead of waiting.
+	FailOnRateLimit bool
+	// Middlewares wrap the transport in order, the first one sees a request first. They run before
+	// the authentication, so the headers they set are covered by the signature.
+	Middlewares []Middleware
+}
+
+// ValidateTLS reports why the TLS options would make every request fail: the configuration cannot be loaded,
+// or the RoundTripper, or the transport of HTTPClient, is not an *http.Transport it can be applied to.
+func (o Options) ValidateTLS() error {
+	if o.TLS == nil {
+		return nil
+	}
+
+	roundTripper := o.RoundTripper
+	if o.HTTPClient != nil {
+		roundTripper = o.HTTPClient.Transport
+	}
+
+	_, err := tlsTransport(roundTripper, *o.TLS)
+
+	return err
+}
+
+// NewHTTPClient builds the HTTP client described by the options.
+func NewHTTPClient(opts Options) *http.Client {
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		roundTripper := opts.RoundTripper
+
+		switch {
+		case roundTripper == nil:
+			roundTripper = newRoundTripper(opts)
+		case opts.TLS != nil:
+			roundTripper = withTLS(roundTripper, *opts.TLS)
+		}
+
+		httpClient = &http.Client{
+			Transport: roundTripper,
+			Timeout:   opts.AttemptTimeout,
+		}
+	} else if opts.TLS != nil {
+		withTLSClient := *httpClient
+		withTLSClient.Transport = withTLS(httpClient.Transport, *opts.TLS)
+		httpClient = &withTLSClient
+	}
+
+	if opts.Signer == nil && opts.TokenSource == nil && opts.RateLimiter == nil && len(opts.Middlewares) == 0 {
+		return httpClient
+	}
+
+	next := httpClient.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	if opts.Signer != nil {
+		next = &SigningRoundTripper{Signer: opts.Signer, Next: next}
+	}
+
+	// Requests are signed after waiting for the rate limiter, so their Date header is current.
+	if opts.RateLimiter != nil {
+		next = &RateLimitRoundTripper{Limiter: opts.RateLimiter, FailFast: opts.FailOnRateLimit, Next: next}
+	}
+
+	if opts.TokenSource != nil {
+		next = &BearerRoundTripper{Source: opts.TokenSource, Next: next}
+	}
+
+	next = Chain(next, opts.Middlewares...)
+
+	wrapped := *httpClient
+	wrapped.Transport = next
+
+	return &wrapped
+}
+
+func newRoundTripper(opts Options) http.RoundTripper {
+	roundTripper := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert
+
+	keepAlive := opts.KeepAlive
+	if keepAlive == 0 {
+		keepAlive = defaultKeepAlive
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   defaultDialTimeout,
+		KeepAlive: keepAlive,
+	}
+
+	roundTripper.DialContext = dialer.DialContext
+	roundTripper.DisableKeepAlives = opts.DisableKeepAlives
+
+	if opts.MaxIdleConns > 0 {
+		roundTripper.MaxIdleConns = opts.MaxIdleConns
+	}
+
+	if opts.MaxIdleConnsPerHost > 0 {
+		roundTripper.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	}
+
+	if opts.IdleConnTimeout > 0 {
+		roundTripper.IdleConnTimeout = opts.IdleConnTimeout
+	}
+
+	if opts.TLS != nil {
+		return withTLS(roundTripper, *opts.TLS)
+	}
+
+	return roundTripper
+}
+
+// withTLS applies the TLS options to a clone of roundTripper, or returns a RoundTripper failing every request
+// when they cannot be applied.
+func withTLS(roundTripper http.RoundTripper, opts TLSOptions) http.RoundTripper {
+	transport, err := tlsTransport(roundTripper, opts)
+	if err != nil {
+		return errRoundTripper{err: err}
+	}
+
+	return transport
+}
@@ -0,0 +1,81 @@
+package http_test
+
+import (
+	http2 "net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/transport/http"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+type countingRoundTripper struct {
+	calls int
+}
+
+func (c *countingRoundTripper) RoundTrip(req *http2.Request) (*http2.Response, error) {
+	c.calls++
+
+	return http2.DefaultTransport.RoundTrip(req)
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	t.Run("Uses the given HTTP client as-is", func(t *testing.T) {
+		httpClient := &http2.Client{Timeout: time.Second}
+		assert.Same(t, httpClient, http.NewHTTPClient(http.Options{HTTPClient: httpClient, AttemptTimeout: time.Minute}))
+	})
+	t.Run("Uses the given RoundTripper", func(t *testing.T) {
+		roundTripper := &countingRoundTripper{}
+		httpClient := http.NewHTTPClient(http.Options{RoundTripper: roundTripper, AttemptTimeout: time.Minute})
+		assert.Same(t, roundTripper, httpClient.Transport)
+		assert.Equal(t, time.Minute, httpClient.Timeout)
+	})
+	t.Run("Applies the connection settings", func(t *testing.T) {
+		httpClient := http.NewHTTPClient(http.Options{
+			MaxIdleConns:        10,
+			MaxIdleConnsPerHost: 5,
+			IdleConnTimeout:     time.Minute,
+			DisableKeepAlives:   true,
+		})
+		roundTripper, ok := httpClient.Transport.(*http2.Transport)
+		assert.True(t, ok, "Expected *http.Transport")
+		assert.Equal(t, 10, roundTripper.MaxIdleConns)
+		assert.Equal(t, 5, roundTripper.MaxIdleConnsPerHost)
+		assert.Equal(t, time.Minute, roundTripper.IdleConnTimeout)
+		assert.True(t, roundTripper.DisableKeepAlives)
+	})
+	t.Run("Keeps the net/http defaults", func(t *testing.T) {
+		httpClient := http.NewHTTPClient(http.Options{})
+		roundTripper, ok := httpClient.Transport.(*http2.Transport)
+		assert.True(t, ok, "Expected *http.Transport")
+		assert.Equal(t, http2.DefaultTransport.(*http2.Transport).MaxIdleConns, roundTripper.MaxIdleConns)
+		assert.Equal(t, time.Duration(0), httpClient.Timeout)
+	})
+}
+
+func TestNewWithOptions(t *testing.T) {
+	server := createMockServer()
+	defer server.Close()
+
+	t.Run("Sends requests through the RoundTripper", func(t *testing.T) {
+		roundTripper := &countingRoundTripper{}
+		transport := http.NewWithOptions(server.URL, "/fetch/", http.Options{RoundTripper: roundTripper})
+
+		_, err := transport.Fetch(context.Background(), "test-id")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, roundTripper.calls)
+	})
+	t.Run("Times out a single attempt", func(t *testing.T) {
+		slowServer := httptest.NewServer(http2.HandlerFunc(func(w http2.ResponseWriter, r *http2.Request) {
+			time.Sleep(100 * time.Millisecond)
+		}))
+		defer slowServer.Close()
+
+		transport := http.NewWithOptions(slowServer.URL, "/", http.Options{AttemptTimeout: 10 * time.Millisecond})
+		_, err := transport.List(context.Background(), &utils.ListAccountsRequest{})
+		assert.Error(t, err)
+	})
+}
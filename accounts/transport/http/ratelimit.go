@@ -0,0 +1,219 @@
+package http
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	errors2 "github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
+)
+
+const (
+	rateLimitRemainingHeader = "X-RateLimit-Remaining"
+	rateLimitResetHeader     = "X-RateLimit-Reset"
+	// minEpochReset tells an X-RateLimit-Reset holding a Unix time from one holding a number of seconds.
+	minEpochReset = 1_000_000_000
+)
+
+// RateLimiter is a token bucket shared by every request of the clients it is given to, safe for concurrent use.
+// It also follows the X-RateLimit-Remaining and X-RateLimit-Reset headers of the API: it holds requests back
+// until the reset once the API reports no remaining requests.
+type RateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	// last is the time tokens was computed at, it is in the future while the API asks to wait for a reset.
+	last time.Time
+	now  func() time.Time
+}
+
+// NewRateLimiter creates a RateLimiter letting requestsPerSecond requests through on average, in bursts of
+// up to burst requests. With a requestsPerSecond of zero, only the rate-limit headers of the API hold requests back.
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// Allow takes a token if one is available right away and reports whether it did.
+func (l *RateLimiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if l.delay(now, 1) > 0 {
+		return false
+	}
+
+	l.take(now)
+
+	return true
+}
+
+// Delay returns how long until a token is available, without taking it.
+func (l *RateLimiter) Delay() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.delay(l.now(), 1)
+}
+
+// Wait takes a token, blocking until one is available or ctx is done. It returns an *errors.ErrRateLimited
+// marked FailFast right away when the wait would outlast the deadline of ctx.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+
+	now := l.now()
+	delay := l.delay(now, 1)
+
+	if deadline, ok := ctx.Deadline(); ok && now.Add(delay).After(deadline) {
+		l.mu.Unlock()
+
+		return &errors2.ErrRateLimited{Wait: delay, FailFast: true}
+	}
+
+	l.take(now)
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens = math.Min(l.tokens+1, l.burst)
+		l.mu.Unlock()
+
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Update adapts the limiter to the rate-limit headers of a response of the API.
+func (l *RateLimiter) Update(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get(rateLimitRemainingHeader))
+	if err != nil {
+		return
+	}
+
+	reset, err := strconv.ParseInt(header.Get(rateLimitResetHeader), 10, 64)
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	resetAt := now.Add(time.Duration(reset) * time.Second)
+	if reset >= minEpochReset {
+		resetAt = time.Unix(reset, 0)
+	}
+
+	if !resetAt.After(now) {
+		return
+	}
+
+	l.advance(now)
+
+	switch {
+	case remaining <= 0:
+		// Nothing goes through before the reset, after which the API allows requests again.
+		l.tokens = l.burst
+		l.last = resetAt
+	case float64(remaining) < l.tokens:
+		l.tokens = float64(remaining)
+	}
+}
+
+// advance adds the tokens accumulated since the last update.
+func (l *RateLimiter) advance(now time.Time) {
+	if !now.After(l.last) {
+		return
+	}
+
+	if l.rate <= 0 {
+		l.tokens = l.burst
+	} else {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+
+	l.last = now
+}
+
+// delay returns how long until n tokens are available.
+func (l *RateLimiter) delay(now time.Time, n float64) time.Duration {
+	l.advance(now)
+
+	var delay time.Duration
+	if l.last.After(now) {
+		delay = l.last.Sub(now)
+	}
+
+	if missing := n - l.tokens; missing > 0 && l.rate > 0 {
+		delay += time.Duration(missing / l.rate * float64(time.Second))
+	}
+
+	return delay
+}
+
+func (l *RateLimiter) take(now time.Time) {
+	l.advance(now)
+	l.tokens--
+}
+
+// RateLimitRoundTripper holds requests back with a RateLimiter before passing them to the next RoundTripper,
+// and updates the limiter from the rate-limit headers of the responses.
+type RateLimitRoundTripper struct {
+	Limiter *RateLimiter
+	// FailFast fails requests with an *errors.ErrRateLimited marked FailFast instead of waiting for the limiter.
+	FailFast bool
+	Next     http.RoundTripper
+}
+
+func (r *RateLimitRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.FailFast {
+		if !r.Limiter.Allow() {
+			closeRequestBody(req)
+
+			return nil, &errors2.ErrRateLimited{Wait: r.Limiter.Delay(), FailFast: true}
+		}
+	} else if err := r.Limiter.Wait(req.Context()); err != nil {
+		closeRequestBody(req)
+
+		return nil, err
+	}
+
+	resp, err := r.Next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Limiter.Update(resp.Header)
+
+	return resp, nil
+}
+
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
@@ -0,0 +1,147 @@
+package http_test
+
+import (
+	"fmt"
+	http2 "net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/transport/http"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func rateLimitHeaders(remaining int, reset int64) http2.Header {
+	header := http2.Header{}
+	header.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+
+	return header
+}
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("Lets bursts through", func(t *testing.T) {
+		limiter := http.NewRateLimiter(10, 3)
+
+		for i := 0; i < 3; i++ {
+			assert.True(t, limiter.Allow())
+		}
+
+		assert.False(t, limiter.Allow())
+		assert.InDelta(t, 100*time.Millisecond, limiter.Delay(), float64(10*time.Millisecond))
+	})
+	t.Run("Waits for a token", func(t *testing.T) {
+		limiter := http.NewRateLimiter(20, 1)
+		start := time.Now()
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, limiter.Wait(context.Background()))
+		}
+
+		assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	})
+	t.Run("Fails fast when the wait would outlast the deadline", func(t *testing.T) {
+		limiter := http.NewRateLimiter(1, 1)
+		require.True(t, limiter.Allow())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := limiter.Wait(ctx)
+
+		var rateLimited *errors.ErrRateLimited
+		require.ErrorAs(t, err, &rateLimited)
+		assert.InDelta(t, time.Second, rateLimited.Wait, float64(10*time.Millisecond))
+		assert.True(t, rateLimited.FailFast)
+		assert.Less(t, time.Since(start), 10*time.Millisecond)
+	})
+	t.Run("Gives the token back when the context is canceled", func(t *testing.T) {
+		limiter := http.NewRateLimiter(10, 1)
+		require.True(t, limiter.Allow())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		assert.ErrorIs(t, limiter.Wait(ctx), context.Canceled)
+		assert.Less(t, limiter.Delay(), 100*time.Millisecond)
+	})
+	t.Run("Waits for the reset when no request remains", func(t *testing.T) {
+		limiter := http.NewRateLimiter(100, 10)
+		limiter.Update(rateLimitHeaders(0, 2))
+
+		assert.False(t, limiter.Allow())
+		assert.InDelta(t, 2*time.Second, limiter.Delay(), float64(50*time.Millisecond))
+	})
+	t.Run("Reads resets given as a Unix time", func(t *testing.T) {
+		limiter := http.NewRateLimiter(0, 1)
+		limiter.Update(rateLimitHeaders(0, time.Now().Add(3*time.Second).Unix()))
+
+		assert.Greater(t, limiter.Delay(), time.Second)
+	})
+	t.Run("Spends no more than the remaining requests", func(t *testing.T) {
+		limiter := http.NewRateLimiter(10, 10)
+		limiter.Update(rateLimitHeaders(1, 60))
+
+		assert.True(t, limiter.Allow())
+		assert.False(t, limiter.Allow())
+	})
+	t.Run("Ignores missing and past headers", func(t *testing.T) {
+		limiter := http.NewRateLimiter(0, 1)
+		limiter.Update(http2.Header{})
+		limiter.Update(rateLimitHeaders(0, time.Now().Add(-time.Minute).Unix()))
+
+		assert.True(t, limiter.Allow())
+		assert.True(t, limiter.Allow(), "Expected no client-side limit with a rate of zero")
+	})
+}
+
+func TestRateLimitedTransport(t *testing.T) {
+	var remaining int
+
+	server := httptest.NewServer(http2.HandlerFunc(func(w http2.ResponseWriter, r *http2.Request) {
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("X-RateLimit-Reset", "1")
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+
+		if r.URL.Path == "/" {
+			fmt.Fprint(w, `{"data": []}`)
+		} else {
+			fmt.Fprint(w, `{"data": {"id": "test-id"}}`)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("Shares the limiter between operations", func(t *testing.T) {
+		remaining = 100
+		transport := http.NewWithOptions(server.URL, "/", http.Options{RateLimiter: http.NewRateLimiter(20, 1)})
+		start := time.Now()
+
+		_, err := transport.List(context.Background(), &utils.ListAccountsRequest{})
+		require.NoError(t, err)
+		_, err = transport.Fetch(context.Background(), "test-id")
+		require.NoError(t, err)
+		_, err = transport.List(context.Background(), &utils.ListAccountsRequest{})
+		require.NoError(t, err)
+
+		assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	})
+	t.Run("Fails fast once the API reports no remaining requests", func(t *testing.T) {
+		remaining = 0
+		transport := http.NewWithOptions(server.URL, "/", http.Options{RateLimiter: http.NewRateLimiter(0, 1), FailOnRateLimit: true})
+
+		_, err := transport.List(context.Background(), &utils.ListAccountsRequest{})
+		require.NoError(t, err)
+
+		_, err = transport.List(context.Background(), &utils.ListAccountsRequest{})
+		var rateLimited *errors.ErrRateLimited
+		require.ErrorAs(t, err, &rateLimited)
+		assert.Greater(t, rateLimited.Wait, 900*time.Millisecond)
+		assert.True(t, rateLimited.FailFast)
+	})
+}
@@ -0,0 +1,294 @@
+package http
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	errors2 "github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
+)
+
+const (
+	requestTarget         = "(request-target)"
+	authorizationHeader   = "Authorization"
+	signatureHeader       = "Signature"
+	algorithmRSASHA256    = "rsa-sha256"
+	algorithmECDSASHA256  = "ecdsa-sha256"
+	digestAlgorithmSHA256 = "SHA-256="
+)
+
+// DefaultSignedHeaders are the headers covered by a signature when a Signer does not list its own.
+var DefaultSignedHeaders = []string{requestTarget, "host", "date", "digest", "content-type"}
+
+// Signer signs requests with HTTP Signatures as described by draft-cavage-http-signatures.
+type Signer struct {
+	// KeyID identifies the key on the server side.
+	KeyID string
+	// Key is an *rsa.PrivateKey, signing with rsa-sha256, or an *ecdsa.PrivateKey, signing with ecdsa-sha256.
+	Key crypto.Signer
+	// Headers lists the headers covered by the signature, DefaultSignedHeaders when empty.
+	// Headers missing from a request, such as content-type on a GET, are left out of its signature.
+	Headers []string
+	// SignatureHeader sends the signature in the Signature header instead of the Authorization header.
+	SignatureHeader bool
+}
+
+// NewSigner creates a Signer for an RSA or ECDSA key.
+func NewSigner(keyID string, key crypto.Signer) (*Signer, error) {
+	if keyID == "" {
+		return nil, errors.New("signer: missing key ID")
+	}
+
+	if _, err := signatureAlgorithm(key); err != nil {
+		return nil, err
+	}
+
+	return &Signer{KeyID: keyID, Key: key}, nil
+}
+
+// ParsePrivateKeyPEM parses a PEM encoded PKCS #1, PKCS #8 or SEC 1 private key.
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("signer: no PEM block found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("signer: unsupported private key type %T", key)
+		}
+
+		return signer, nil
+	default:
+		return nil, fmt.Errorf("signer: unsupported PEM block type %q", block.Type)
+	}
+}
+
+// LoadPrivateKeyFile reads and parses a PEM encoded private key file.
+func LoadPrivateKeyFile(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParsePrivateKeyPEM(data)
+}
+
+// Sign sets the Date and Digest headers when they are missing and adds the signature of the request.
+func (s *Signer) Sign(req *http.Request) error {
+	algorithm, err := signatureAlgorithm(s.Key)
+	if err != nil {
+		return err
+	}
+
+	if req.Header.Get("Date") == "" {
+		req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	}
+
+	if req.Header.Get("Digest") == "" {
+		digest, err := bodyDigest(req)
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Digest", digest)
+	}
+
+	headers := s.Headers
+	if len(headers) == 0 {
+		headers = DefaultSignedHeaders
+	}
+
+	signed := make([]string, 0, len(headers))
+
+	for _, header := range headers {
+		header = strings.ToLower(header)
+		if header == requestTarget || header == "host" || req.Header.Get(header) != "" {
+			signed = append(signed, header)
+		}
+	}
+
+	hashed := sha256.Sum256([]byte(signingString(req, signed)))
+
+	var signature []byte
+
+	switch key := s.Key.(type) {
+	case *ecdsa.PrivateKey:
+		signature, err = ecdsa.SignASN1(rand.Reader, key, hashed[:])
+	default:
+		signature, err = s.Key.Sign(rand.Reader, hashed[:], crypto.SHA256)
+	}
+
+	if err != nil {
+		return fmt.Errorf("signer: %w", err)
+	}
+
+	params := fmt.Sprintf(`keyId="%s",algorithm="%s",headers="%s",signature="%s"`,
+		s.KeyID, algorithm, strings.Join(signed, " "), base64.StdEncoding.EncodeToString(signature))
+
+	if s.SignatureHeader {
+		req.Header.Set(signatureHeader, params)
+	} else {
+		req.Header.Set(authorizationHeader, "Signature "+params)
+	}
+
+	return nil
+}
+
+// VerifySignature checks the signature and the digest of a request signed by a Signer against the public key.
+func VerifySignature(req *http.Request, publicKey crypto.PublicKey) error {
+	value := req.Header.Get(signatureHeader)
+	if value == "" {
+		value = strings.TrimPrefix(req.Header.Get(authorizationHeader), "Signature ")
+	}
+
+	params := parseSignatureParams(value)
+	if params["signature"] == "" || params["headers"] == "" {
+		return errors.New("signer: missing signature")
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil {
+		return fmt.Errorf("signer: invalid signature encoding: %w", err)
+	}
+
+	if digest := req.Header.Get("Digest"); digest != "" {
+		expected, err := bodyDigest(req)
+		if err != nil {
+			return err
+		}
+
+		if digest != expected {
+			return errors.New("signer: digest does not match the body")
+		}
+	}
+
+	hashed := sha256.Sum256([]byte(signingString(req, strings.Fields(params["headers"]))))
+
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		err = rsa.VerifyPKCS1v15(key, crypto.SHA256, hashed[:], signature)
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, hashed[:], signature) {
+			err = errors.New("verification error")
+		}
+	default:
+		err = fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+
+	if err != nil {
+		return fmt.Errorf("signer: %w", err)
+	}
+
+	return nil
+}
+
+// SigningRoundTripper signs every request before passing it to the next RoundTripper. A request that
+// cannot be signed fails with an *errors.ErrPermanentFailure, signing it again would fail the same way.
+type SigningRoundTripper struct {
+	Signer *Signer
+	Next   http.RoundTripper
+}
+
+func (s *SigningRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	signed := req.Clone(req.Context())
+	if err := s.Signer.Sign(signed); err != nil {
+		return nil, &errors2.ErrPermanentFailure{Detail: fmt.Sprintf("failed to sign request: %v", err)}
+	}
+
+	return s.Next.RoundTrip(signed)
+}
+
+func signatureAlgorithm(key crypto.Signer) (string, error) {
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		return algorithmRSASHA256, nil
+	case *ecdsa.PrivateKey:
+		return algorithmECDSASHA256, nil
+	default:
+		return "", fmt.Errorf("signer: unsupported key type %T", key)
+	}
+}
+
+func signingString(req *http.Request, headers []string) string {
+	lines := make([]string, 0, len(headers))
+
+	for _, header := range headers {
+		switch header {
+		case requestTarget:
+			lines = append(lines, fmt.Sprintf("%s: %s %s", requestTarget, strings.ToLower(req.Method), req.URL.RequestURI()))
+		case "host":
+			host := req.Host
+			if host == "" {
+				host = req.URL.Host
+			}
+
+			lines = append(lines, "host: "+host)
+		default:
+			lines = append(lines, header+": "+strings.Join(req.Header.Values(header), ", "))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// bodyDigest returns the SHA-256 Digest header value of the request body, leaving the body readable.
+func bodyDigest(req *http.Request) (string, error) {
+	var body []byte
+
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return "", fmt.Errorf("signer: failed to read request body: %w", err)
+		}
+
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	sum := sha256.Sum256(body)
+
+	return digestAlgorithmSHA256 + base64.StdEncoding.EncodeToString(sum[:]), nil
+}
+
+func parseSignatureParams(value string) map[string]string {
+	params := make(map[string]string)
+
+	for _, part := range strings.Split(value, ",") {
+		name, quoted, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok {
+			params[name] = strings.Trim(quoted, `"`)
+		}
+	}
+
+	return params
+}
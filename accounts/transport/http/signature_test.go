@@ -0,0 +1,152 @@
+package http_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	http2 "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/models"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/transport/http"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func generateKeys(t *testing.T) map[string][]byte {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	require.NoError(t, err)
+	sec1, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+
+	return map[string][]byte{
+		"PKCS #1 RSA": pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}),
+		"PKCS #8 RSA": pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}),
+		"SEC 1 ECDSA": pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}),
+	}
+}
+
+func TestSigner(t *testing.T) {
+	for name, keyPEM := range generateKeys(t) {
+		keyPEM := keyPEM
+		t.Run(name, func(t *testing.T) {
+			key, err := http.ParsePrivateKeyPEM(keyPEM)
+			require.NoError(t, err)
+			signer, err := http.NewSigner("test-key-id", key)
+			require.NoError(t, err)
+
+			var verifyErr error
+			var authorization string
+
+			server := httptest.NewServer(http2.HandlerFunc(func(w http2.ResponseWriter, r *http2.Request) {
+				authorization = r.Header.Get("Authorization")
+				verifyErr = http.VerifySignature(r, key.Public())
+				w.Header().Set("Content-Type", "application/vnd.api+json")
+				w.Write([]byte(`{"data": {"id": "test-id"}}`)) //nolint:errcheck
+			}))
+			defer server.Close()
+
+			transport := http.NewWithOptions(server.URL, "/accounts/", http.Options{Signer: signer})
+
+			_, err = transport.Create(context.Background(), &utils.CreateAccountRequest{Data: models.AccountData{ID: "test-id"}})
+			assert.NoError(t, err)
+			assert.NoError(t, verifyErr)
+			assert.True(t, strings.HasPrefix(authorization, `Signature keyId="test-key-id",algorithm=`))
+			assert.Contains(t, authorization, `headers="(request-target) host date digest content-type"`)
+
+			_, err = transport.Fetch(context.Background(), "test-id")
+			assert.NoError(t, err)
+			assert.NoError(t, verifyErr)
+			assert.Contains(t, authorization, `headers="(request-target) host date digest"`)
+		})
+	}
+}
+
+func TestSignerSignatureHeader(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	signer := &http.Signer{KeyID: "test-key-id", Key: key, Headers: []string{"(request-target)", "date"}, SignatureHeader: true}
+	req, err := http2.NewRequest(http2.MethodGet, "http://localhost/v1/organisation/accounts?page%5Bsize%5D=1", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, signer.Sign(req))
+	assert.Empty(t, req.Header.Get("Authorization"))
+	assert.Contains(t, req.Header.Get("Signature"), `headers="(request-target) date"`)
+	assert.NotEmpty(t, req.Header.Get("Date"))
+	assert.Equal(t, "SHA-256=47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=", req.Header.Get("Digest"))
+	assert.NoError(t, http.VerifySignature(req, key.Public()))
+}
+
+func TestVerifySignatureFailures(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	newSignedRequest := func() *http2.Request {
+		req, err := http2.NewRequest(http2.MethodPost, "http://localhost/v1/organisation/accounts", bytes.NewReader([]byte(`{"data": {}}`)))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/vnd.api+json")
+		require.NoError(t, (&http.Signer{KeyID: "test-key-id", Key: key}).Sign(req))
+
+		return req
+	}
+
+	testCases := []struct {
+		name      string
+		tamper    func(req *http2.Request)
+		publicKey crypto.PublicKey
+	}{
+		{name: "unsigned request", tamper: func(req *http2.Request) { req.Header.Del("Authorization") }, publicKey: key.Public()},
+		{name: "tampered body", tamper: func(req *http2.Request) { req.Body = io.NopCloser(strings.NewReader(`{}`)) }, publicKey: key.Public()},
+		{name: "tampered header", tamper: func(req *http2.Request) { req.Header.Set("Content-Type", "text/plain") }, publicKey: key.Public()},
+		{name: "other key", tamper: func(req *http2.Request) {}, publicKey: otherKey.Public()},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := newSignedRequest()
+			tc.tamper(req)
+			assert.Error(t, http.VerifySignature(req, tc.publicKey))
+		})
+	}
+}
+
+func TestLoadPrivateKeyFile(t *testing.T) {
+	keys := generateKeys(t)
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, keys["SEC 1 ECDSA"], 0o600))
+
+	key, err := http.LoadPrivateKeyFile(path)
+	assert.NoError(t, err)
+	assert.IsType(t, &ecdsa.PrivateKey{}, key)
+
+	_, err = http.LoadPrivateKeyFile(filepath.Join(t.TempDir(), "missing.pem"))
+	assert.Error(t, err)
+
+	_, err = http.ParsePrivateKeyPEM([]byte("not a key"))
+	assert.Error(t, err)
+
+	_, err = http.NewSigner("", key)
+	assert.Error(t, err)
+}
@@ -0,0 +1,387 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	errors2 "github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
+)
+
+// TLSOptions configures the TLS connections to the API, for a private CA or mutual TLS.
+// Certificates given as files are reloaded on the next handshake after the files change on disk.
+type TLSOptions struct {
+	// CAFile is a PEM bundle of the certificate authorities trusted to sign the server certificate.
+	CAFile string
+	// CAPEM is a PEM bundle used in place of CAFile. The system roots are trusted when neither is set.
+	CAPEM []byte
+	// CertFile and KeyFile hold the PEM encoded client certificate and private key for mutual TLS.
+	CertFile string
+	KeyFile  string
+	// CertPEM and KeyPEM are a PEM encoded client certificate and private key used in place of the files.
+	CertPEM []byte
+	KeyPEM  []byte
+	// MinVersion is the minimum TLS version accepted, tls.VersionTLS12 when zero.
+	MinVersion uint16
+	// ServerName overrides the host name the server certificate is verified against.
+	ServerName string
+	// PinnedSPKIHashes are the base64 encoded SHA-256 hashes of the subject public key info of trusted certificates.
+	// When set, a connection is only accepted if a certificate of its verified chain matches one of them.
+	PinnedSPKIHashes []string
+}
+
+// NewTLSConfig builds the TLS configuration described by the options,
+// it fails when a certificate or key cannot be loaded or a pin is malformed.
+// With a CAFile, the server certificate is verified against ServerName, or else the host name sent with SNI,
+// so a connection to an IP address fails unless ServerName is set. The transports built from Options verify
+// it against the dialed host instead.
+func NewTLSConfig(opts TLSOptions) (*tls.Config, error) {
+	config, _, err := newTLSConfig(opts)
+
+	return config, err
+}
+
+// newTLSConfig builds the configuration of NewTLSConfig and returns the verifier of its VerifyConnection,
+// nil when crypto/tls verifies the connections alone.
+func newTLSConfig(opts TLSOptions) (*tls.Config, *connectionVerifier, error) {
+	minVersion := opts.MinVersion
+	if minVersion == 0 {
+		minVersion = tls.VersionTLS12
+	}
+
+	config := &tls.Config{
+		MinVersion: minVersion,
+		ServerName: opts.ServerName,
+	}
+
+	pins, err := parsePins(opts.PinnedSPKIHashes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case opts.CertFile != "" || opts.KeyFile != "":
+		if opts.CertFile == "" || opts.KeyFile == "" {
+			return nil, nil, errors.New("tls: both a certificate and a key file are required")
+		}
+
+		reloader := &certificateReloader{certFile: opts.CertFile, keyFile: opts.KeyFile}
+		if _, err := reloader.GetClientCertificate(nil); err != nil {
+			return nil, nil, err
+		}
+
+		config.GetClientCertificate = reloader.GetClientCertificate
+	case len(opts.CertPEM) > 0 || len(opts.KeyPEM) > 0:
+		certificate, err := tls.X509KeyPair(opts.CertPEM, opts.KeyPEM)
+		if err != nil {
+			return nil, nil, fmt.Errorf("tls: invalid client certificate: %w", err)
+		}
+
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	var roots *caReloader
+
+	switch {
+	case opts.CAFile != "":
+		roots = &caReloader{file: opts.CAFile}
+		if _, err := roots.Pool(); err != nil {
+			return nil, nil, err
+		}
+	case len(opts.CAPEM) > 0:
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(opts.CAPEM) {
+			return nil, nil, errors.New("tls: no CA certificate found")
+		}
+
+		config.RootCAs = pool
+	}
+
+	if roots == nil && len(pins) == 0 {
+		return config, nil, nil
+	}
+
+	if roots != nil {
+		// The roots can change between handshakes, so the chain is verified in VerifyConnection
+		// against the current pool instead of by crypto/tls against a fixed one.
+		config.InsecureSkipVerify = true
+	}
+
+	verifier := &connectionVerifier{roots: roots, pins: pins}
+	config.VerifyConnection = verifier.forHost(opts.ServerName)
+
+	return config, verifier, nil
+}
+
+// connectionVerifier verifies the server chain against the current CA pool, when it is reloaded
+// from a file, and checks the pins.
+type connectionVerifier struct {
+	roots *caReloader
+	pins  map[string]bool
+}
+
+// forHost returns a VerifyConnection verifying the server certificate against serverName,
+// the host name sent with SNI when empty.
+func (v *connectionVerifier) forHost(serverName string) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		chains := state.VerifiedChains
+
+		if v.roots != nil {
+			var err error
+			if chains, err = v.roots.verify(state, serverName); err != nil {
+				return err
+			}
+		}
+
+		return checkPins(chains, v.pins)
+	}
+}
+
+// errTLSRoundTripper is returned when TLS options come with a RoundTripper they cannot be applied to.
+var errTLSRoundTripper = errors.New("tls: TLS options only apply to an *http.Transport, configure the TLS of other RoundTrippers on their own")
+
+// tlsTransport returns a clone of base, http.DefaultTransport when nil, using the TLS configuration of opts.
+// It fails when base is not an *http.Transport or the configuration cannot be loaded.
+func tlsTransport(base http.RoundTripper, opts TLSOptions) (*http.Transport, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	transport, ok := base.(*http.Transport)
+	if !ok {
+		return nil, errTLSRoundTripper
+	}
+
+	config, verifier, err := newTLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	transport = transport.Clone()
+	transport.TLSClientConfig = config
+
+	if verifier != nil && verifier.roots != nil {
+		dial := transport.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{Timeout: defaultDialTimeout, KeepAlive: defaultKeepAlive}).DialContext
+		}
+
+		transport.DialTLSContext = dialTLSContext(dial, config, verifier)
+	}
+
+	return transport, nil
+}
+
+// dialTLSContext returns a DialTLSContext verifying the server certificate against the dialed host
+// when config has no ServerName. crypto/tls sends no SNI for an IP address, so VerifyConnection
+// cannot learn the host from the connection state.
+func dialTLSContext(
+	dial func(ctx context.Context, network, addr string) (net.Conn, error),
+	config *tls.Config,
+	verifier *connectionVerifier,
+) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		hostConfig := config.Clone()
+
+		if hostConfig.ServerName == "" {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				host = addr
+			}
+
+			hostConfig.ServerName = host
+			hostConfig.VerifyConnection = verifier.forHost(host)
+		}
+
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConn := tls.Client(conn, hostConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+
+			return nil, err
+		}
+
+		return tlsConn, nil
+	}
+}
+
+// SPKIHash returns the base64 encoded SHA-256 hash of the subject public key info of a certificate, as used by TLSOptions.PinnedSPKIHashes.
+func SPKIHash(certificate *x509.Certificate) string {
+	sum := sha256.Sum256(certificate.RawSubjectPublicKeyInfo)
+
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func parsePins(hashes []string) (map[string]bool, error) {
+	pins := make(map[string]bool, len(hashes))
+
+	for _, hash := range hashes {
+		sum, err := base64.StdEncoding.DecodeString(hash)
+		if err != nil || len(sum) != sha256.Size {
+			return nil, fmt.Errorf("tls: invalid SPKI pin %q", hash)
+		}
+
+		pins[hash] = true
+	}
+
+	return pins, nil
+}
+
+func checkPins(chains [][]*x509.Certificate, pins map[string]bool) error {
+	if len(pins) == 0 {
+		return nil
+	}
+
+	for _, chain := range chains {
+		for _, certificate := range chain {
+			if pins[SPKIHash(certificate)] {
+				return nil
+			}
+		}
+	}
+
+	return errors.New("tls: no certificate of the server chain matches a pinned SPKI hash")
+}
+
+// fileStamp identifies a version of a file on disk.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func stampOf(file string) (fileStamp, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return fileStamp{}, fmt.Errorf("tls: %w", err)
+	}
+
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// certificateReloader loads a client certificate and reloads it when its files change.
+type certificateReloader struct {
+	certFile    string
+	keyFile     string
+	mu          sync.Mutex
+	certStamp   fileStamp
+	keyStamp    fileStamp
+	certificate *tls.Certificate
+}
+
+// GetClientCertificate returns the current certificate. While the files are being replaced and
+// do not form a valid pair yet, the previously loaded certificate is kept.
+func (r *certificateReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	certStamp, certErr := stampOf(r.certFile)
+	keyStamp, keyErr := stampOf(r.keyFile)
+
+	if certErr == nil && keyErr == nil && r.certificate != nil && certStamp == r.certStamp && keyStamp == r.keyStamp {
+		return r.certificate, nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		if r.certificate != nil {
+			return r.certificate, nil
+		}
+
+		return nil, fmt.Errorf("tls: invalid client certificate: %w", err)
+	}
+
+	r.certificate, r.certStamp, r.keyStamp = &certificate, certStamp, keyStamp
+
+	return r.certificate, nil
+}
+
+// caReloader loads a CA bundle and reloads it when its file changes.
+type caReloader struct {
+	file  string
+	mu    sync.Mutex
+	stamp fileStamp
+	pool  *x509.CertPool
+}
+
+// Pool returns the current CA pool, the previously loaded pool is kept while the file is invalid.
+func (r *caReloader) Pool() (*x509.CertPool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stamp, err := stampOf(r.file)
+	if err == nil && r.pool != nil && stamp == r.stamp {
+		return r.pool, nil
+	}
+
+	data, err := os.ReadFile(r.file)
+	if err == nil {
+		pool := x509.NewCertPool()
+		if pool.AppendCertsFromPEM(data) {
+			r.pool, r.stamp = pool, stamp
+
+			return pool, nil
+		}
+
+		err = errors.New("no CA certificate found")
+	}
+
+	if r.pool != nil {
+		return r.pool, nil
+	}
+
+	return nil, fmt.Errorf("tls: invalid CA bundle: %w", err)
+}
+
+func (r *caReloader) verify(state tls.ConnectionState, serverName string) ([][]*x509.Certificate, error) {
+	if len(state.PeerCertificates) == 0 {
+		return nil, errors.New("tls: server sent no certificate")
+	}
+
+	pool, err := r.Pool()
+	if err != nil {
+		return nil, err
+	}
+
+	if serverName == "" {
+		serverName = state.ServerName
+	}
+
+	if serverName == "" {
+		return nil, errors.New("tls: no host name to verify the server certificate against, set TLSOptions.ServerName")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, certificate := range state.PeerCertificates[1:] {
+		intermediates.AddCert(certificate)
+	}
+
+	return state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         pool,
+		Intermediates: intermediates,
+		DNSName:       serverName,
+	})
+}
+
+// errRoundTripper fails every request, it stands in for a transport that could not be configured.
+type errRoundTripper struct {
+	err error
+}
+
+func (e errRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	closeRequestBody(req)
+
+	return nil, &errors2.ErrPermanentFailure{Detail: e.err.Error()}
+}
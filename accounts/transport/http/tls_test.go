@@ -0,0 +1,372 @@
+package http_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	http2 "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/transport/http"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+type testCertificate struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	certPEM     []byte
+	keyPEM      []byte
+}
+
+func (c *testCertificate) tlsCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+
+	certificate, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	require.NoError(t, err)
+
+	return certificate
+}
+
+// issueCertificate creates a certificate signed by parent, or a self-signed CA when parent is nil.
+// It is valid for the given IP addresses and host names, 127.0.0.1 when none is given.
+func issueCertificate(t *testing.T, commonName string, parent *testCertificate, hosts ...string) *testCertificate {
+	t.Helper()
+
+	if len(hosts) == 0 {
+		hosts = []string{"127.0.0.1"}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signer, signerKey = parent.certificate, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return &testCertificate{
+		certificate: certificate,
+		key:         key,
+		certPEM:     pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:      pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// newMTLSServer starts a server presenting a certificate of ca and requiring a client certificate of ca.
+// It answers with the common name of the client certificate.
+func newMTLSServer(t *testing.T, ca *testCertificate) *httptest.Server {
+	t.Helper()
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.certificate)
+
+	server := httptest.NewUnstartedServer(http2.HandlerFunc(func(w http2.ResponseWriter, r *http2.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		fmt.Fprintf(w, `{"data": [], "meta": {"client": %q}}`, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{issueCertificate(t, "server", ca).tlsCertificate(t)},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func writeFile(t *testing.T, file string, data []byte, modTime time.Time) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(file, data, 0o600))
+	require.NoError(t, os.Chtimes(file, modTime, modTime))
+}
+
+func listClient(t *testing.T, baseURL string, tlsOptions http.TLSOptions) (string, error) {
+	t.Helper()
+
+	return listClientWithOptions(t, baseURL, http.Options{TLS: &tlsOptions, DisableKeepAlives: true})
+}
+
+func listClientWithOptions(t *testing.T, baseURL string, opts http.Options) (string, error) {
+	t.Helper()
+
+	transport := http.NewWithOptions(baseURL, "/", opts)
+
+	resp, err := transport.List(context.Background(), &utils.ListAccountsRequest{})
+	if err != nil {
+		return "", err
+	}
+
+	client, _ := resp.Meta["client"].(string)
+
+	return client, nil
+}
+
+func TestTLSOptions(t *testing.T) {
+	ca := issueCertificate(t, "ca", nil)
+	client := issueCertificate(t, "client-1", ca)
+	server := newMTLSServer(t, ca)
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client.key")
+	writeFile(t, caFile, ca.certPEM, time.Now())
+	writeFile(t, certFile, client.certPEM, time.Now())
+	writeFile(t, keyFile, client.keyPEM, time.Now())
+
+	t.Run("Connects with a client certificate from files", func(t *testing.T) {
+		name, err := listClient(t, server.URL, http.TLSOptions{CAFile: caFile, CertFile: certFile, KeyFile: keyFile})
+		assert.NoError(t, err)
+		assert.Equal(t, "client-1", name)
+	})
+	t.Run("Connects with a client certificate from bytes", func(t *testing.T) {
+		name, err := listClient(t, server.URL, http.TLSOptions{CAPEM: ca.certPEM, CertPEM: client.certPEM, KeyPEM: client.keyPEM})
+		assert.NoError(t, err)
+		assert.Equal(t, "client-1", name)
+	})
+	t.Run("Fails without a client certificate", func(t *testing.T) {
+		_, err := listClient(t, server.URL, http.TLSOptions{CAPEM: ca.certPEM})
+		assert.Error(t, err)
+	})
+	t.Run("Fails with a server certificate of another CA", func(t *testing.T) {
+		other := issueCertificate(t, "other-ca", nil)
+		_, err := listClient(t, server.URL, http.TLSOptions{CAPEM: other.certPEM, CertPEM: client.certPEM, KeyPEM: client.keyPEM})
+		assert.Error(t, err)
+	})
+	t.Run("Accepts a chain matching a pin", func(t *testing.T) {
+		name, err := listClient(t, server.URL, http.TLSOptions{
+			CAFile:           caFile,
+			CertPEM:          client.certPEM,
+			KeyPEM:           client.keyPEM,
+			PinnedSPKIHashes: []string{http.SPKIHash(ca.certificate)},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "client-1", name)
+	})
+	t.Run("Rejects a chain matching no pin", func(t *testing.T) {
+		_, err := listClient(t, server.URL, http.TLSOptions{
+			CAPEM:            ca.certPEM,
+			CertPEM:          client.certPEM,
+			KeyPEM:           client.keyPEM,
+			PinnedSPKIHashes: []string{http.SPKIHash(client.certificate)},
+		})
+		assert.ErrorContains(t, err, "pinned SPKI hash")
+	})
+	t.Run("Enforces the minimum TLS version", func(t *testing.T) {
+		server.TLS.MaxVersion = tls.VersionTLS12
+		defer func() { server.TLS.MaxVersion = 0 }()
+
+		_, err := listClient(t, server.URL, http.TLSOptions{
+			CAPEM:      ca.certPEM,
+			CertPEM:    client.certPEM,
+			KeyPEM:     client.keyPEM,
+			MinVersion: tls.VersionTLS13,
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestTLSOptionsHostVerification(t *testing.T) {
+	ca := issueCertificate(t, "ca", nil)
+	client := issueCertificate(t, "client-1", ca)
+
+	// The server on 127.0.0.1 presents a certificate of the trusted CA issued for other hosts.
+	server := newMTLSServer(t, ca)
+	server.TLS.Certificates = []tls.Certificate{issueCertificate(t, "server", ca, "10.9.9.9", "api.example.com").tlsCertificate(t)}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writeFile(t, caFile, ca.certPEM, time.Now())
+
+	t.Run("Rejects a certificate of another IP address with a CA file", func(t *testing.T) {
+		_, err := listClient(t, server.URL, http.TLSOptions{CAFile: caFile, CertPEM: client.certPEM, KeyPEM: client.keyPEM})
+		assert.ErrorContains(t, err, "127.0.0.1")
+	})
+	t.Run("Accepts the certificate of ServerName", func(t *testing.T) {
+		name, err := listClient(t, server.URL, http.TLSOptions{
+			CAFile:     caFile,
+			CertPEM:    client.certPEM,
+			KeyPEM:     client.keyPEM,
+			ServerName: "api.example.com",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "client-1", name)
+	})
+	t.Run("Rejects a certificate of another host name with a CA file", func(t *testing.T) {
+		_, err := listClient(t, server.URL, http.TLSOptions{
+			CAFile:     caFile,
+			CertPEM:    client.certPEM,
+			KeyPEM:     client.keyPEM,
+			ServerName: "accounts.example.com",
+		})
+		assert.ErrorContains(t, err, "accounts.example.com")
+	})
+	t.Run("Fails without a host name when used on its own", func(t *testing.T) {
+		config, err := http.NewTLSConfig(http.TLSOptions{CAFile: caFile, CertPEM: client.certPEM, KeyPEM: client.keyPEM})
+		require.NoError(t, err)
+
+		httpClient := &http2.Client{Transport: &http2.Transport{TLSClientConfig: config, DisableKeepAlives: true}}
+		_, err = httpClient.Get(server.URL)
+		assert.ErrorContains(t, err, "no host name")
+	})
+}
+
+func TestTLSOptionsCustomTransport(t *testing.T) {
+	ca := issueCertificate(t, "ca", nil)
+	client := issueCertificate(t, "client-1", ca)
+	server := newMTLSServer(t, ca)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writeFile(t, caFile, ca.certPEM, time.Now())
+
+	tlsOptions := &http.TLSOptions{CAFile: caFile, CertPEM: client.certPEM, KeyPEM: client.keyPEM}
+
+	t.Run("Applies to a clone of an *http.Transport RoundTripper", func(t *testing.T) {
+		var dialed int
+
+		dialer := &net.Dialer{}
+		roundTripper := &http2.Transport{
+			DisableKeepAlives: true,
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				dialed++
+
+				return dialer.DialContext(ctx, network, addr)
+			},
+		}
+
+		opts := http.Options{RoundTripper: roundTripper, TLS: tlsOptions}
+		require.NoError(t, opts.ValidateTLS())
+
+		name, err := listClientWithOptions(t, server.URL, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, "client-1", name)
+		assert.Equal(t, 1, dialed, "the dialer of the supplied transport should be kept")
+		assert.Nil(t, roundTripper.TLSClientConfig, "the supplied transport should not be modified")
+	})
+	t.Run("Applies to the default transport of an HTTPClient", func(t *testing.T) {
+		opts := http.Options{HTTPClient: &http2.Client{Timeout: time.Minute}, TLS: tlsOptions}
+		require.NoError(t, opts.ValidateTLS())
+
+		name, err := listClientWithOptions(t, server.URL, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, "client-1", name)
+		assert.Nil(t, opts.HTTPClient.Transport, "the supplied client should not be modified")
+	})
+	t.Run("Rejects another RoundTripper", func(t *testing.T) {
+		roundTripper := &countingRoundTripper{}
+		opts := http.Options{RoundTripper: roundTripper, TLS: tlsOptions}
+		assert.ErrorContains(t, opts.ValidateTLS(), "*http.Transport")
+
+		_, err := listClientWithOptions(t, server.URL, opts)
+		assert.ErrorContains(t, err, "*http.Transport")
+		assert.Zero(t, roundTripper.calls)
+	})
+}
+
+func TestTLSOptionsReload(t *testing.T) {
+	ca := issueCertificate(t, "ca", nil)
+	server := newMTLSServer(t, ca)
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client.key")
+
+	first := issueCertificate(t, "client-1", ca)
+	writeFile(t, caFile, ca.certPEM, time.Now())
+	writeFile(t, certFile, first.certPEM, time.Now())
+	writeFile(t, keyFile, first.keyPEM, time.Now())
+
+	transport := http.NewWithOptions(server.URL, "/", http.Options{
+		TLS:               &http.TLSOptions{CAFile: caFile, CertFile: certFile, KeyFile: keyFile},
+		DisableKeepAlives: true,
+	})
+	clientName := func() string {
+		resp, err := transport.List(context.Background(), &utils.ListAccountsRequest{})
+		require.NoError(t, err)
+
+		return resp.Meta["client"].(string) //nolint:forcetypeassert
+	}
+
+	assert.Equal(t, "client-1", clientName())
+
+	// A half-written rotation keeps the previous certificate.
+	second := issueCertificate(t, "client-2", ca)
+	writeFile(t, certFile, second.certPEM, time.Now().Add(time.Minute))
+	assert.Equal(t, "client-1", clientName())
+
+	writeFile(t, keyFile, second.keyPEM, time.Now().Add(time.Minute))
+	assert.Equal(t, "client-2", clientName())
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("Defaults to TLS 1.2", func(t *testing.T) {
+		config, err := http.NewTLSConfig(http.TLSOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	})
+	t.Run("Requires both a certificate and a key file", func(t *testing.T) {
+		_, err := http.NewTLSConfig(http.TLSOptions{CertFile: filepath.Join(dir, "client.pem")})
+		assert.ErrorContains(t, err, "both a certificate and a key file")
+	})
+	t.Run("Fails on missing files", func(t *testing.T) {
+		_, err := http.NewTLSConfig(http.TLSOptions{CAFile: filepath.Join(dir, "missing.pem")})
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+	t.Run("Fails on a CA bundle without certificates", func(t *testing.T) {
+		_, err := http.NewTLSConfig(http.TLSOptions{CAPEM: []byte("not a certificate")})
+		assert.ErrorContains(t, err, "no CA certificate found")
+	})
+	t.Run("Fails on a malformed pin", func(t *testing.T) {
+		_, err := http.NewTLSConfig(http.TLSOptions{PinnedSPKIHashes: []string{"c2hvcnQ="}})
+		assert.ErrorContains(t, err, "invalid SPKI pin")
+	})
+	t.Run("Makes every request fail when used in Options", func(t *testing.T) {
+		_, err := listClient(t, "https://127.0.0.1:1", http.TLSOptions{CAFile: filepath.Join(dir, "missing.pem")})
+		assert.ErrorContains(t, err, "missing.pem")
+	})
+}
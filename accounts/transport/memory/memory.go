@@ -0,0 +1,384 @@
+// Package memory provides a transport.Transport keeping accounts in memory, for unit tests that need the
+// behaviour of the API without running it. It assigns versions and timestamps, paginates and filters lists,
+// and returns the same errors as the HTTP transport does for the answers of the API.
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/models"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/transport"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/utils"
+)
+
+const (
+	basePath        = "/v1/organisation/accounts"
+	accountType     = "accounts"
+	defaultPageSize = 100
+)
+
+// filterAttributes returns the value of each attribute accepted in filter[attribute].
+var filterAttributes = map[string]func(attributes *models.AccountAttributes) string{
+	"bank_id":        func(a *models.AccountAttributes) string { return a.BankID },
+	"bank_id_code":   func(a *models.AccountAttributes) string { return a.BankIDCode },
+	"account_number": func(a *models.AccountAttributes) string { return a.AccountNumber },
+	"iban":           func(a *models.AccountAttributes) string { return a.Iban },
+	"customer_id":    func(a *models.AccountAttributes) string { return a.CustomerID },
+	"country": func(a *models.AccountAttributes) string {
+		if a.Country == nil {
+			return ""
+		}
+
+		return *a.Country
+	},
+}
+
+// Transport is an in-memory transport.Transport, safe for concurrent use. The zero value is an empty store.
+type Transport struct {
+	// Now returns the creation and modification times of the accounts, time.Now when nil.
+	Now func() time.Time
+
+	mu       sync.RWMutex
+	accounts map[string]models.AccountData
+	order    []string
+}
+
+var _ transport.Transport = (*Transport)(nil)
+
+// New creates an in-memory transport holding the given accounts.
+func New(accounts ...models.AccountData) *Transport {
+	t := &Transport{}
+	t.Seed(accounts...)
+
+	return t
+}
+
+// Seed stores the accounts as they are, replacing any account with the same ID. Accounts without a version get version 0.
+func (t *Transport) Seed(accounts ...models.AccountData) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, account := range accounts {
+		account = clone(account)
+		if account.Version == nil {
+			account.Version = int64Ptr(0)
+		}
+
+		t.put(account)
+	}
+}
+
+// Account returns a copy of the stored account with the given ID.
+func (t *Transport) Account(id string) (models.AccountData, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	account, ok := t.accounts[id]
+	if !ok {
+		return models.AccountData{}, false
+	}
+
+	return clone(account), true
+}
+
+// Accounts returns a copy of the stored accounts, in the order they were created.
+func (t *Transport) Accounts() []models.AccountData {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	accounts := make([]models.AccountData, 0, len(t.order))
+	for _, id := range t.order {
+		accounts = append(accounts, clone(t.accounts[id]))
+	}
+
+	return accounts
+}
+
+// Reset removes every account.
+func (t *Transport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.accounts, t.order = nil, nil
+}
+
+func (t *Transport) Create(ctx context.Context, req *utils.CreateAccountRequest) (*utils.CreateAccountResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	account := clone(req.Data)
+	if err := validate(&account); err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.accounts[account.ID]; ok {
+		return nil, &errors.ErrConflict{Detail: "Account cannot be created as it violates a duplicate constraint"}
+	}
+
+	now := t.now()
+	account.Version = int64Ptr(0)
+	account.CreatedOn = &now
+	account.ModifiedOn = &now
+	account.Links = &models.ResourceLinks{Self: selfLink(account.ID)}
+	t.put(account)
+
+	return &utils.CreateAccountResponse{
+		Document: utils.Document{Links: utils.Links{Self: selfLink(account.ID)}},
+		Data:     clone(account),
+	}, nil
+}
+
+func (t *Transport) Fetch(ctx context.Context, accountID string) (*utils.FetchAccountResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	account, ok := t.accounts[accountID]
+	if !ok {
+		return nil, &errors.ErrNotFound{ResourceID: accountID}
+	}
+
+	return &utils.FetchAccountResponse{
+		Document: utils.Document{Links: utils.Links{Self: selfLink(accountID)}},
+		Data:     clone(account),
+	}, nil
+}
+
+func (t *Transport) Delete(ctx context.Context, req *utils.DeleteAccountRequest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	account, ok := t.accounts[req.ID]
+	if !ok {
+		return &errors.ErrNotFound{ResourceID: req.ID}
+	}
+
+	if *account.Version != req.Version {
+		return &errors.ErrConflict{Detail: "invalid version"}
+	}
+
+	delete(t.accounts, req.ID)
+
+	for i, id := range t.order {
+		if id == req.ID {
+			t.order = append(t.order[:i:i], t.order[i+1:]...)
+
+			break
+		}
+	}
+
+	return nil
+}
+
+func (t *Transport) Update(ctx context.Context, req *utils.UpdateAccountRequest) (*utils.UpdateAccountResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stored, ok := t.accounts[req.Data.ID]
+	if !ok {
+		return nil, &errors.ErrNotFound{ResourceID: req.Data.ID}
+	}
+
+	if *stored.Version != req.Data.Version {
+		return nil, &errors.ErrConflict{Detail: "invalid version"}
+	}
+
+	account := clone(stored)
+	if account.Attributes == nil {
+		account.Attributes = &models.AccountAttributes{}
+	}
+
+	update := req.Data.Attributes
+
+	if update.AccountMatchingOptOut != nil {
+		account.Attributes.AccountMatchingOptOut = boolPtr(*update.AccountMatchingOptOut)
+	}
+
+	if update.AlternativeNames != nil {
+		account.Attributes.AlternativeNames = append([]string(nil), *update.AlternativeNames...)
+	}
+
+	if len(update.Name) > 0 {
+		account.Attributes.Name = append([]string(nil), update.Name...)
+	}
+
+	if update.Status != nil {
+		status := *update.Status
+		account.Attributes.Status = &status
+	}
+
+	now := t.now()
+	account.Version = int64Ptr(*stored.Version + 1)
+	account.ModifiedOn = &now
+	t.put(account)
+
+	return &utils.UpdateAccountResponse{
+		Document: utils.Document{Links: utils.Links{Self: selfLink(account.ID)}},
+		Data:     clone(account),
+	}, nil
+}
+
+func (t *Transport) List(ctx context.Context, req *utils.ListAccountsRequest) (*utils.ListAccountsResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
+	if req.PageNumber < 0 {
+		return nil, &errors.ErrBadRequest{Detail: "page[number] must not be negative"}
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var matching []models.AccountData
+
+	for _, id := range t.order {
+		if account := t.accounts[id]; matches(account, req.Filter) {
+			matching = append(matching, account)
+		}
+	}
+
+	lastPage := 0
+	if len(matching) > 0 {
+		lastPage = (len(matching) - 1) / pageSize
+	}
+
+	resp := &utils.ListAccountsResponse{
+		Document: utils.Document{Links: utils.Links{
+			Self:  pageLink(req, req.PageNumber, pageSize),
+			First: pageLink(req, 0, pageSize),
+			Last:  pageLink(req, lastPage, pageSize),
+		}},
+		Data: []models.AccountData{},
+	}
+
+	if req.PageNumber < lastPage {
+		resp.Links.Next = pageLink(req, req.PageNumber+1, pageSize)
+	}
+
+	if req.PageNumber > 0 {
+		resp.Links.Prev = pageLink(req, req.PageNumber-1, pageSize)
+	}
+
+	for i := req.PageNumber * pageSize; i < len(matching) && i < (req.PageNumber+1)*pageSize; i++ {
+		resp.Data = append(resp.Data, clone(matching[i]))
+	}
+
+	return resp, nil
+}
+
+func (t *Transport) put(account models.AccountData) {
+	if t.accounts == nil {
+		t.accounts = make(map[string]models.AccountData)
+	}
+
+	if _, ok := t.accounts[account.ID]; !ok {
+		t.order = append(t.order, account.ID)
+	}
+
+	t.accounts[account.ID] = account
+}
+
+func (t *Transport) now() time.Time {
+	if t.Now != nil {
+		return t.Now().UTC()
+	}
+
+	return time.Now().UTC()
+}
+
+// validate rejects the accounts the API answers with 400 Bad Request.
+func validate(account *models.AccountData) error {
+	switch {
+	case account.ID == "":
+		return &errors.ErrBadRequest{Detail: "validation failure list: [id in body is required]"}
+	case account.OrganisationID == "":
+		return &errors.ErrBadRequest{Detail: "validation failure list: [organisation_id in body is required]"}
+	case account.Type != accountType:
+		return &errors.ErrBadRequest{Detail: "validation failure list: [type in body should be one of [accounts]]"}
+	case account.Attributes == nil:
+		return &errors.ErrBadRequest{Detail: "validation failure list: [attributes in body is required]"}
+	case account.Attributes.Country == nil || *account.Attributes.Country == "":
+		return &errors.ErrBadRequest{Detail: "validation failure list: [country in body is required]"}
+	case len(account.Attributes.Name) == 0:
+		return &errors.ErrBadRequest{Detail: "validation failure list: [name in body is required]"}
+	}
+
+	return nil
+}
+
+func matches(account models.AccountData, filter utils.AccountFilter) bool {
+	for attribute, value := range filter.Params() {
+		if account.Attributes == nil || filterAttributes[attribute](account.Attributes) != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func selfLink(id string) string {
+	return basePath + "/" + id
+}
+
+func pageLink(req *utils.ListAccountsRequest, number, size int) string {
+	query := url.Values{}
+	query.Set("page[number]", strconv.Itoa(number))
+	query.Set("page[size]", strconv.Itoa(size))
+
+	for attribute, value := range req.Filter.Params() {
+		query.Set("filter["+attribute+"]", value)
+	}
+
+	return basePath + "?" + query.Encode()
+}
+
+// clone deep copies an account through its JSON encoding, so callers never share memory with the store.
+func clone(account models.AccountData) models.AccountData {
+	data, err := json.Marshal(account)
+	if err != nil {
+		panic(fmt.Sprintf("memory: failed to copy account %s: %v", account.ID, err))
+	}
+
+	var copied models.AccountData
+	if err := json.Unmarshal(data, &copied); err != nil {
+		panic(fmt.Sprintf("memory: failed to copy account %s: %v", account.ID, err))
+	}
+
+	return copied
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
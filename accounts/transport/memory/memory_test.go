@@ -0,0 +1,211 @@
+package memory_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/models"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/retry"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/transport/memory"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/utils"
+	"github.com/aabri-assignments/form3-accounts/v1/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccount(id, country string) models.AccountData {
+	return models.AccountData{
+		ID:             id,
+		OrganisationID: "eb0bd6f5-c3f5-44b2-b677-acd23cdde73c",
+		Type:           "accounts",
+		Attributes: &models.AccountAttributes{
+			Country: &country,
+			Name:    []string{"Samantha Holder"},
+		},
+	}
+}
+
+func createRequest(account models.AccountData) *utils.CreateAccountRequest {
+	return &utils.CreateAccountRequest{Data: account}
+}
+
+func TestTransport(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("Creates and fetches an account", func(t *testing.T) {
+		store := memory.New()
+		store.Now = func() time.Time { return now }
+
+		created, err := store.Create(ctx, createRequest(newAccount("account-1", "GB")))
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), *created.Data.Version)
+		assert.Equal(t, now, *created.Data.CreatedOn)
+		assert.Equal(t, "/v1/organisation/accounts/account-1", created.Links.Self)
+
+		fetched, err := store.Fetch(ctx, "account-1")
+		require.NoError(t, err)
+		assert.Equal(t, created.Data, fetched.Data)
+	})
+	t.Run("Rejects duplicate IDs", func(t *testing.T) {
+		store := memory.New(newAccount("account-1", "GB"))
+
+		_, err := store.Create(ctx, createRequest(newAccount("account-1", "FR")))
+		var conflict *errors.ErrConflict
+		assert.ErrorAs(t, err, &conflict)
+	})
+	t.Run("Rejects invalid accounts", func(t *testing.T) {
+		account := newAccount("account-1", "GB")
+		account.OrganisationID = ""
+
+		_, err := memory.New().Create(ctx, createRequest(account))
+		var badRequest *errors.ErrBadRequest
+		assert.ErrorAs(t, err, &badRequest)
+	})
+	t.Run("Returns ErrNotFound for unknown IDs", func(t *testing.T) {
+		store := memory.New()
+		var notFound *errors.ErrNotFound
+
+		_, err := store.Fetch(ctx, "unknown")
+		assert.ErrorAs(t, err, &notFound)
+		assert.ErrorAs(t, store.Delete(ctx, &utils.DeleteAccountRequest{ID: "unknown"}), &notFound)
+		_, err = store.Update(ctx, &utils.UpdateAccountRequest{Data: utils.UpdateAccountData{ID: "unknown"}})
+		assert.ErrorAs(t, err, &notFound)
+	})
+	t.Run("Deletes the current version only", func(t *testing.T) {
+		store := memory.New(newAccount("account-1", "GB"))
+		var conflict *errors.ErrConflict
+
+		assert.ErrorAs(t, store.Delete(ctx, &utils.DeleteAccountRequest{ID: "account-1", Version: 1}), &conflict)
+		assert.NoError(t, store.Delete(ctx, &utils.DeleteAccountRequest{ID: "account-1", Version: 0}))
+
+		_, ok := store.Account("account-1")
+		assert.False(t, ok)
+	})
+	t.Run("Updates the current version and increments it", func(t *testing.T) {
+		store := memory.New(newAccount("account-1", "GB"))
+		status := "closed"
+		update := &utils.UpdateAccountRequest{Data: utils.UpdateAccountData{
+			ID:         "account-1",
+			Attributes: models.AccountAttributesUpdate{Name: []string{"Sam Holder"}, Status: &status},
+		}}
+
+		updated, err := store.Update(ctx, update)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), *updated.Data.Version)
+		assert.Equal(t, []string{"Sam Holder"}, updated.Data.Attributes.Name)
+		assert.Equal(t, "closed", *updated.Data.Attributes.Status)
+		assert.Equal(t, "GB", *updated.Data.Attributes.Country)
+
+		_, err = store.Update(ctx, update)
+		var conflict *errors.ErrConflict
+		assert.ErrorAs(t, err, &conflict)
+	})
+	t.Run("Paginates and filters lists", func(t *testing.T) {
+		store := memory.New()
+		for i := 0; i < 5; i++ {
+			country := "GB"
+			if i%2 == 1 {
+				country = "FR"
+			}
+
+			_, err := store.Create(ctx, createRequest(newAccount(fmt.Sprintf("account-%d", i), country)))
+			require.NoError(t, err)
+		}
+
+		page, err := store.List(ctx, &utils.ListAccountsRequest{PageNumber: 1, PageSize: 2})
+		require.NoError(t, err)
+		assert.Equal(t, "account-2", page.Data[0].ID)
+		assert.Equal(t, "account-3", page.Data[1].ID)
+		next, err := utils.PageNumber(page.Links.Next)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, next)
+		prev, err := utils.PageNumber(page.Links.Prev)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, prev)
+
+		filtered, err := store.List(ctx, &utils.ListAccountsRequest{Filter: utils.AccountFilter{Country: "GB"}})
+		require.NoError(t, err)
+		assert.Len(t, filtered.Data, 3)
+		assert.False(t, filtered.HasNext())
+
+		empty, err := store.List(ctx, &utils.ListAccountsRequest{Filter: utils.AccountFilter{Country: "DE"}})
+		require.NoError(t, err)
+		assert.NotNil(t, empty.Data)
+		assert.Empty(t, empty.Data)
+
+		_, err = store.List(ctx, &utils.ListAccountsRequest{PageNumber: -1})
+		var badRequest *errors.ErrBadRequest
+		assert.ErrorAs(t, err, &badRequest)
+	})
+	t.Run("Does not share memory with callers", func(t *testing.T) {
+		account := newAccount("account-1", "GB")
+		store := memory.New(account)
+
+		account.Attributes.Name[0] = "Changed"
+		fetched, err := store.Fetch(ctx, "account-1")
+		require.NoError(t, err)
+		fetched.Data.Attributes.Name[0] = "Changed"
+
+		stored, _ := store.Account("account-1")
+		assert.Equal(t, "Samantha Holder", stored.Attributes.Name[0])
+	})
+	t.Run("Stops when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := memory.New(newAccount("account-1", "GB")).Fetch(ctx, "account-1")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestTransportConcurrency(t *testing.T) {
+	store := memory.New()
+	client := &accounts.AccountClient{
+		Transport: store,
+		Retry:     retry.NewConstantBackOff(time.Millisecond, 1),
+		Logger:    &logging.Leveled{Level: logging.LevelError},
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			account := newAccount(fmt.Sprintf("account-%d", i), "GB")
+			_, err := client.Create(&account)
+			assert.NoError(t, err)
+			assert.NoError(t, client.DeleteLatest(context.Background(), account.ID, accounts.DeleteLatestOptions{}))
+		}(i)
+	}
+
+	// The same account is created by every goroutine, exactly one of them succeeds.
+	var created int32
+	var mu sync.Mutex
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			account := newAccount("shared", "GB")
+			if _, err := store.Create(context.Background(), createRequest(account)); err == nil {
+				mu.Lock()
+				created++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int32(1), created)
+	assert.Len(t, store.Accounts(), 1)
+}
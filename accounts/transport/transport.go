@@ -11,4 +11,6 @@ type Transport interface {
 	Create(context context.Context, req *utils.CreateAccountRequest) (*utils.CreateAccountResponse, error)
 	Fetch(context context.Context, accountID string) (*utils.FetchAccountResponse, error)
 	Delete(context context.Context, req *utils.DeleteAccountRequest) error
+	Update(context context.Context, req *utils.UpdateAccountRequest) (*utils.UpdateAccountResponse, error)
+	List(context context.Context, req *utils.ListAccountsRequest) (*utils.ListAccountsResponse, error)
 }
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
+)
+
+// filterAttribute is an account attribute the API filters lists on, with the format of its values.
+type filterAttribute struct {
+	name    string
+	pattern *regexp.Regexp
+	value   func(f *AccountFilter) *string
+}
+
+var filterAttributes = []filterAttribute{
+	{name: "bank_id", pattern: regexp.MustCompile(`^[A-Z0-9]{1,16}$`), value: func(f *AccountFilter) *string { return &f.BankID }},
+	{name: "bank_id_code", pattern: regexp.MustCompile(`^[A-Z]{1,16}$`), value: func(f *AccountFilter) *string { return &f.BankIDCode }},
+	{name: "account_number", pattern: regexp.MustCompile(`^[A-Z0-9]{1,64}$`), value: func(f *AccountFilter) *string { return &f.AccountNumber }},
+	{name: "iban", pattern: regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]{1,64}$`), value: func(f *AccountFilter) *string { return &f.Iban }},
+	{name: "country", pattern: regexp.MustCompile(`^[A-Z]{2}$`), value: func(f *AccountFilter) *string { return &f.Country }},
+	{name: "customer_id", pattern: regexp.MustCompile(`^[A-Za-z0-9-]{1,256}$`), value: func(f *AccountFilter) *string { return &f.CustomerID }},
+}
+
+// AccountFilter is a typed set of server-side filters applied when listing accounts, see accounts.Filter.
+// Empty fields are not sent.
+type AccountFilter struct {
+	BankID        string
+	BankIDCode    string
+	AccountNumber string
+	Iban          string
+	Country       string
+	CustomerID    string
+}
+
+// Validate checks every non-empty filter value against the format accepted by the API.
+func (f AccountFilter) Validate() error {
+	for _, attribute := range filterAttributes {
+		if value := *attribute.value(&f); value != "" && !attribute.pattern.MatchString(value) {
+			return &errors.ErrBadRequest{Detail: fmt.Sprintf("invalid filter[%s]: %q", attribute.name, value)}
+		}
+	}
+
+	return nil
+}
+
+// Params returns the non-empty filters keyed by the account attribute they filter on.
+func (f AccountFilter) Params() map[string]string {
+	params := make(map[string]string)
+
+	for _, attribute := range filterAttributes {
+		if value := *attribute.value(&f); value != "" {
+			params[attribute.name] = value
+		}
+	}
+
+	return params
+}
+
+// Set sets the filter on the named account attribute, as in filter[attribute], and reports whether
+// the attribute is one the API filters on.
+func (f *AccountFilter) Set(attribute, value string) bool {
+	for _, filterAttribute := range filterAttributes {
+		if filterAttribute.name == attribute {
+			*filterAttribute.value(f) = value
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// Apply validates the filter and sets it on the list request.
+func (f AccountFilter) Apply(req *ListAccountsRequest) error {
+	if err := f.Validate(); err != nil {
+		return err
+	}
+
+	req.Filter = f
+
+	return nil
+}
@@ -19,6 +19,27 @@ type DeleteAccountRequest struct {
 	Version int64
 }
 
+// UpdateAccountRequest represents the JSON:API PATCH document for updating an account.
+type UpdateAccountRequest struct {
+	Data UpdateAccountData `json:"data"`
+}
+
+// UpdateAccountData carries the version the update applies to and the changed attributes only.
+type UpdateAccountData struct {
+	ID         string                         `json:"id"`
+	Type       string                         `json:"type"`
+	Version    int64                          `json:"version"`
+	Attributes models.AccountAttributesUpdate `json:"attributes"`
+}
+
+// ListAccountsRequest represents the request structure for listing a page of accounts.
+// Filter holds the values sent as filter[attribute] query parameters.
+type ListAccountsRequest struct {
+	PageNumber int
+	PageSize   int
+	Filter     AccountFilter
+}
+
 // EncodeJSONRequest encodes a JSON request body.
 func EncodeJSONRequest(requestBody interface{}) (io.Reader, error) {
 	body, err := json.Marshal(requestBody)
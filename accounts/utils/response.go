@@ -3,20 +3,67 @@ package utils
 import (
 	"encoding/json"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/aabri-assignments/form3-accounts/v1/accounts/models"
 )
 
+// Document represents the top-level members of a JSON:API document other than data.
+type Document struct {
+	Links Links                  `json:"links,omitempty"`
+	Meta  map[string]interface{} `json:"meta,omitempty"`
+}
+
 // CreateAccountResponse represents the response structure for creating an account.
 type CreateAccountResponse struct {
+	Document
 	Data models.AccountData `json:"data"`
 }
 
 // FetchAccountResponse represents the response structure for fetching an account.
 type FetchAccountResponse struct {
+	Document
+	Data models.AccountData `json:"data"`
+}
+
+// UpdateAccountResponse represents the response structure for updating an account.
+type UpdateAccountResponse struct {
+	Document
 	Data models.AccountData `json:"data"`
 }
 
+// Links represents the top-level JSON:API links, self for a single resource
+// and the pagination links for a list of resources.
+type Links struct {
+	Self  string `json:"self,omitempty"`
+	First string `json:"first,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Last  string `json:"last,omitempty"`
+}
+
+// ListAccountsResponse represents the response structure for listing a page of accounts.
+type ListAccountsResponse struct {
+	Document
+	Data []models.AccountData `json:"data"`
+}
+
+// HasNext reports whether there is a page after this one.
+func (r *ListAccountsResponse) HasNext() bool {
+	return r.Links.Next != ""
+}
+
+// PageNumber extracts the page[number] query parameter from a pagination link.
+func PageNumber(link string) (int, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(u.Query().Get("page[number]"))
+}
+
 // DecodeJSONResponse decodes a JSON response body.
 func DecodeJSONResponse(resp *http.Response, responseBody interface{}) error {
 	return json.NewDecoder(resp.Body).Decode(responseBody)
@@ -63,3 +63,75 @@ func TestCloseResponse(t *testing.T) {
 		assert.Equal(t, "EOF", readErr.Error())
 	})
 }
+
+func TestListAccountsResponse(t *testing.T) {
+	t.Run("Decodes data and links", func(t *testing.T) {
+		resp := &http.Response{
+			Body: io.NopCloser(bytes.NewReader([]byte(`{
+				"data": [{"id": "first-id"}, {"id": "second-id"}],
+				"links": {
+					"first": "/v1/organisation/accounts?page%5Bnumber%5D=first",
+					"next": "/v1/organisation/accounts?page%5Bnumber%5D=2&page%5Bsize%5D=2",
+					"prev": "/v1/organisation/accounts?page%5Bnumber%5D=0&page%5Bsize%5D=2",
+					"last": "/v1/organisation/accounts?page%5Bnumber%5D=last",
+					"self": "/v1/organisation/accounts?page%5Bnumber%5D=1&page%5Bsize%5D=2"
+				}
+			}`))),
+		}
+
+		var decoded utils.ListAccountsResponse
+		err := utils.DecodeJSONResponse(resp, &decoded)
+
+		assert.NoError(t, err)
+		assert.Len(t, decoded.Data, 2)
+		assert.Equal(t, "second-id", decoded.Data[1].ID)
+		assert.True(t, decoded.HasNext())
+		assert.NotEmpty(t, decoded.Links.First)
+		assert.NotEmpty(t, decoded.Links.Prev)
+		assert.NotEmpty(t, decoded.Links.Last)
+	})
+
+	t.Run("HasNext is false without a next link", func(t *testing.T) {
+		decoded := utils.ListAccountsResponse{}
+		assert.False(t, decoded.HasNext())
+	})
+}
+
+func TestPageNumber(t *testing.T) {
+	t.Run("Parses the page number of a link", func(t *testing.T) {
+		number, err := utils.PageNumber("/v1/organisation/accounts?page%5Bnumber%5D=3&page%5Bsize%5D=100")
+		assert.NoError(t, err)
+		assert.Equal(t, 3, number)
+	})
+
+	t.Run("Error on a link without a numeric page", func(t *testing.T) {
+		_, err := utils.PageNumber("/v1/organisation/accounts?page%5Bnumber%5D=last")
+		assert.Error(t, err)
+	})
+}
+
+func TestFetchAccountResponse(t *testing.T) {
+	t.Run("Decodes the full JSON:API document", func(t *testing.T) {
+		resp := &http.Response{
+			Body: io.NopCloser(bytes.NewReader([]byte(`{
+				"data": {
+					"id": "test-id",
+					"type": "accounts",
+					"links": {"self": "/v1/organisation/accounts/test-id"},
+					"relationships": {"master_account": {"data": [{"id": "master-id", "type": "accounts"}]}}
+				},
+				"links": {"self": "/v1/organisation/accounts/test-id"},
+				"meta": {"request_id": "abc"}
+			}`))),
+		}
+
+		var decoded utils.FetchAccountResponse
+		err := utils.DecodeJSONResponse(resp, &decoded)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "/v1/organisation/accounts/test-id", decoded.Links.Self)
+		assert.Equal(t, "abc", decoded.Meta["request_id"])
+		assert.Equal(t, "/v1/organisation/accounts/test-id", decoded.Data.Links.Self)
+		assert.Equal(t, "master-id", decoded.Data.Relationships["master_account"].Data[0].ID)
+	})
+}
@@ -0,0 +1,25 @@
+// Command fakeapi serves a fake of the Form3 accounts API backed by memory, in place of the docker image.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/aabri-assignments/form3-accounts/v1/pkg/fakeapi"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	flag.Parse()
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           fakeapi.New(nil),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("fake accounts API listening on %s", *addr)
+	log.Fatal(server.ListenAndServe())
+}
@@ -1,20 +1,48 @@
 package accounts
 
 import (
+	"context"
 	"math/rand"
+	"net/http/httptest"
+	"os"
 	"time"
 
+	"github.com/aabri-assignments/form3-accounts/v1"
 	"github.com/aabri-assignments/form3-accounts/v1/accounts"
 	"github.com/aabri-assignments/form3-accounts/v1/accounts/models"
+	"github.com/aabri-assignments/form3-accounts/v1/pkg/fakeapi"
 	"github.com/google/uuid"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
 
 const (
-	testBaseURL = "http://accountapi:8080"
+	// baseURLEnv names the environment variable holding the URL of the API under test.
+	// The suite runs against an in-process fake API when it is not set.
+	baseURLEnv         = "ACCOUNTS_API_URL"
+	healthTimeout      = 60 * time.Second
+	healthPollInterval = 5 * time.Second
 )
 
+var testBaseURL string
+
+var _ = BeforeSuite(func() {
+	testBaseURL = os.Getenv(baseURLEnv)
+	if testBaseURL == "" {
+		server := httptest.NewServer(fakeapi.New(nil))
+		DeferCleanup(server.Close)
+		testBaseURL = server.URL
+	}
+
+	form3Client, err := form3.NewForm3(accounts.Options{BaseURL: testBaseURL})
+	Expect(err).NotTo(HaveOccurred())
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthTimeout)
+	defer cancel()
+
+	Expect(form3Client.WaitUntilHealthy(ctx, healthPollInterval)).To(Succeed())
+})
+
 var _ = DescribeAccount("[Create]", func() {
 	var client accounts.Client
 	BeforeEach(func() {
@@ -76,11 +104,11 @@ var _ = DescribeAccount("[Create]", func() {
 	}
 	DescribeTable("Create Account", args,
 		Entry("should create a UK Account With Confirmation of Payee",
-			"GB", "GBP", "123456", "GBDSC", "EXMPLGB2XXX", []string{"Test Account"}, "12345678", "GB28NWBK12345678901234", false, "Personal"),
+			"GB", "GBP", "123456", "GBDSC", "EXMPLGB2XXX", []string{"Test Account"}, "12345678", "GB52EXMP12345612345678", false, "Personal"),
 		Entry("should Create a UK Account Without Confirmation of Payee",
-			"GB", "GBP", "123456", "GBDSC", "EXMPLGB2XXX", []string{"Test Account"}, "12345678", "GB28NWBK12345678901234", false, ""),
+			"GB", "GBP", "123456", "GBDSC", "EXMPLGB2XXX", []string{"Test Account"}, "12345678", "GB52EXMP12345612345678", false, ""),
 		Entry("should fail with invalid account number",
-			"GB", "GBP", "123456", "GBDSC", "EXMPLGB2XXX", []string{"Test Account"}, "invalid", "GB28NWBK12345678901234", true, ""),
+			"GB", "GBP", "123456", "GBDSC", "EXMPLGB2XXX", []string{"Test Account"}, "invalid", "GB52EXMP12345612345678", true, ""),
 		Entry("should fail with invalid IBAN",
 			"GB", "GBP", "123456", "GBDSC", "EXMPLGB2XXX", []string{"Test Account"}, "12345678", "invalid", true, ""),
 	)
@@ -115,7 +143,7 @@ var _ = DescribeAccount("[Create With Retries]", func() {
 					Bic:                     "EXMPLGB2XXX",
 					AccountNumber:           "12345678",
 					AlternativeNames:        []string{"AltName1", "AltName2"},
-					Iban:                    "GB11NWBK56000123456789",
+					Iban:                    "GB52EXMP12345612345678",
 					Name:                    []string{"John Smith"},
 					SecondaryIdentification: "1234",
 				},
@@ -187,7 +215,7 @@ var _ = DescribeAccount("[Fetch]", func() {
 				Bic:                     "EXMPLGB2XXX",
 				AccountNumber:           "12345678",
 				AlternativeNames:        []string{"AltName1", "AltName2"},
-				Iban:                    "GB11NWBK56000123456789",
+				Iban:                    "GB52EXMP12345612345678",
 				Name:                    []string{"John Smith"},
 				SecondaryIdentification: "1234",
 			},
@@ -251,7 +279,7 @@ var _ = DescribeAccount("[Fetch With Retries]", func() {
 					Bic:                     "EXMPLGB2XXX",
 					AccountNumber:           "12345678",
 					AlternativeNames:        []string{"AltName1", "AltName2"},
-					Iban:                    "GB11NWBK56000123456789",
+					Iban:                    "GB52EXMP12345612345678",
 					Name:                    []string{"John Smith"},
 					SecondaryIdentification: "1234",
 				},
@@ -329,7 +357,7 @@ var _ = DescribeAccount("[Delete]", func() {
 				Bic:                     "EXMPLGB2XXX",
 				AccountNumber:           "12345678",
 				AlternativeNames:        []string{"AltName1", "AltName2"},
-				Iban:                    "GB11NWBK56000123456789",
+				Iban:                    "GB52EXMP12345612345678",
 				Name:                    []string{"John Smith"},
 				SecondaryIdentification: "1234",
 			},
@@ -387,7 +415,7 @@ var _ = DescribeAccount("[Delete With Retries]", func() {
 					Bic:                     "EXMPLGB2XXX",
 					AccountNumber:           "12345678",
 					AlternativeNames:        []string{"AltName1", "AltName2"},
-					Iban:                    "GB11NWBK56000123456789",
+					Iban:                    "GB52EXMP12345612345678",
 					Name:                    []string{"John Smith"},
 					SecondaryIdentification: "1234",
 				},
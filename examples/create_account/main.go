@@ -50,7 +50,7 @@ func main() {
 			AccountMatchingOptOut:   &out,
 			AccountNumber:           "12345678",
 			AlternativeNames:        []string{"AltName1", "AltName2"},
-			Iban:                    "GB11NWBK56000123456789",
+			Iban:                    "GB52EXMP12345612345678",
 			JointAccount:            &jointAccount,
 			Name:                    []string{"John Smith"},
 			SecondaryIdentification: "1234",
@@ -1,16 +1,32 @@
 package form3
 
-import "github.com/aabri-assignments/form3-accounts/v1/accounts"
+import (
+	"net/http"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts"
+	transporthttp "github.com/aabri-assignments/form3-accounts/v1/accounts/transport/http"
+)
 
 // Form3 is a struct that represents a client for the Form3 Accounts API.
 type Form3 struct {
-	options accounts.Options
+	options    accounts.Options
+	httpClient *http.Client
 }
 
 // NewForm3 creates a new Form3 client with the specified base URL.
+// It fails when the TLS configuration of options.HTTP cannot be loaded or applied, or the retry strategy is unknown.
 func NewForm3(options accounts.Options) (*Form3, error) {
+	if _, err := options.NewRetryPolicy(); err != nil {
+		return nil, err
+	}
+
+	if err := options.HTTP.ValidateTLS(); err != nil {
+		return nil, err
+	}
+
 	return &Form3{
-		options: options,
+		options:    options,
+		httpClient: transporthttp.NewHTTPClient(options.HTTP),
 	}, nil
 }
 
@@ -1,10 +1,12 @@
 package form3_test
 
 import (
+	http2 "net/http"
 	"testing"
 
 	"github.com/aabri-assignments/form3-accounts/v1"
 	"github.com/aabri-assignments/form3-accounts/v1/accounts"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/transport/http"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -29,3 +31,56 @@ func TestAccounts(t *testing.T) {
 
 	assert.NotNil(t, accountsClient, "Accounts should return a valid accounts.Client")
 }
+
+func TestNewForm3InvalidTLS(t *testing.T) {
+	options := accounts.Options{
+		BaseURL: "https://accountapi:8443",
+		HTTP: http.Options{
+			TLS: &http.TLSOptions{CertFile: "client.pem"},
+		},
+	}
+
+	form3Client, err := form3.NewForm3(options)
+
+	assert.Error(t, err, "NewForm3 should report an invalid TLS configuration")
+	assert.Nil(t, form3Client)
+}
+
+type roundTripperFunc func(req *http2.Request) (*http2.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http2.Request) (*http2.Response, error) {
+	return f(req)
+}
+
+func TestNewForm3TLSWithCustomRoundTripper(t *testing.T) {
+	options := accounts.Options{
+		BaseURL: "https://accountapi:8443",
+		HTTP: http.Options{
+			RoundTripper: roundTripperFunc(http2.DefaultTransport.RoundTrip),
+			TLS:          &http.TLSOptions{},
+		},
+	}
+
+	form3Client, err := form3.NewForm3(options)
+
+	assert.Error(t, err, "NewForm3 should report TLS options it cannot apply to the RoundTripper")
+	assert.Nil(t, form3Client)
+
+	options.HTTP.RoundTripper = &http2.Transport{}
+	form3Client, err = form3.NewForm3(options)
+
+	assert.NoError(t, err, "NewForm3 should apply TLS options to an *http.Transport")
+	assert.NotNil(t, form3Client)
+}
+
+func TestNewForm3UnknownRetryStrategy(t *testing.T) {
+	options := accounts.Options{
+		BaseURL:       "http://accountapi:8080",
+		RetryStrategy: "quadratic",
+	}
+
+	form3Client, err := form3.NewForm3(options)
+
+	assert.EqualError(t, err, `unknown retry strategy "quadratic"`)
+	assert.Nil(t, form3Client)
+}
@@ -0,0 +1,80 @@
+package form3
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/retry"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/utils"
+	"github.com/aabri-assignments/form3-accounts/v1/pkg/logging"
+)
+
+const (
+	healthPath = "/v1/health"
+	statusUp   = "up"
+)
+
+// HealthStatus is the status reported by the health endpoint of the API.
+type HealthStatus struct {
+	Status string `json:"status"`
+}
+
+// IsUp reports whether the API is up and ready to serve requests.
+func (s *HealthStatus) IsUp() bool {
+	return s.Status == statusUp
+}
+
+// Health calls the health endpoint of the API and returns the reported status.
+func (f *Form3) Health(ctx context.Context) (*HealthStatus, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, f.options.BaseURL+healthPath, nil)
+	if err != nil {
+		return nil, &errors.ErrBadRequest{Detail: "failed to create HTTP request"}
+	}
+
+	resp, err := f.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := errors.HandleHTTPError(resp); err != nil {
+		return nil, err
+	}
+
+	var status HealthStatus
+	if err := utils.DecodeJSONResponse(resp, &status); err != nil {
+		return nil, &errors.ErrPermanentFailure{Detail: "failed to unmarshal response body"}
+	}
+
+	return &status, nil
+}
+
+// WaitUntilHealthy polls the health endpoint every interval until the API reports it is up
+// or the context is done. The interval must be positive.
+func (f *Form3) WaitUntilHealthy(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return &errors.ErrBadRequest{Detail: fmt.Sprintf("invalid health check interval %s", interval)}
+	}
+
+	logger := &logging.Leveled{Level: f.options.LogLevel}
+
+	operation := func() error {
+		status, err := f.Health(ctx)
+		if err != nil {
+			// Every failure is formatted rather than wrapped, so none of them stops the polling.
+			return fmt.Errorf("health check failed: %v", err) //nolint:errorlint
+		}
+
+		if !status.IsUp() {
+			return fmt.Errorf("health check failed: status is %q", status.Status)
+		}
+
+		return nil
+	}
+
+	return retry.RetryWithContext(ctx, operation, retry.NewConstantBackOff(interval, math.MaxInt), logger)
+}
@@ -0,0 +1,89 @@
+package form3_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aabri-assignments/form3-accounts/v1"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts"
+	errs "github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/health", r.URL.Path)
+		fmt.Fprint(w, `{"status": "up"}`)
+	}))
+	defer server.Close()
+
+	form3Client, _ := form3.NewForm3(accounts.Options{BaseURL: server.URL})
+	status, err := form3Client.Health(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "up", status.Status)
+	assert.True(t, status.IsUp())
+}
+
+func TestWaitUntilHealthy(t *testing.T) {
+	t.Run("Waits until the API is up", func(t *testing.T) {
+		var calls int32
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch atomic.AddInt32(&calls, 1) {
+			case 1:
+				w.WriteHeader(http.StatusServiceUnavailable)
+				fmt.Fprint(w, `{"message": "starting"}`)
+			case 2:
+				fmt.Fprint(w, `{"status": "down"}`)
+			default:
+				fmt.Fprint(w, `{"status": "up"}`)
+			}
+		}))
+		defer server.Close()
+
+		form3Client, _ := form3.NewForm3(accounts.Options{BaseURL: server.URL})
+		err := form3Client.WaitUntilHealthy(context.Background(), time.Millisecond)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+	t.Run("Gives up when the context is done", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"status": "down"}`)
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		form3Client, _ := form3.NewForm3(accounts.Options{BaseURL: server.URL})
+		err := form3Client.WaitUntilHealthy(ctx, 10*time.Millisecond)
+
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+	t.Run("Rejects an interval that is not positive", func(t *testing.T) {
+		var calls int32
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			fmt.Fprint(w, `{"status": "down"}`)
+		}))
+		defer server.Close()
+
+		form3Client, _ := form3.NewForm3(accounts.Options{BaseURL: server.URL})
+
+		for _, interval := range []time.Duration{0, -time.Second} {
+			err := form3Client.WaitUntilHealthy(context.Background(), interval)
+			assert.IsType(t, &errs.ErrBadRequest{}, err)
+		}
+
+		assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+	})
+}
@@ -0,0 +1,259 @@
+// Package fakeapi serves a fake of the v1 accounts endpoints of the Form3 API, backed by an in-memory store.
+// A Server is an http.Handler, so it runs in process with httptest.NewServer or on its own with cmd/fakeapi.
+package fakeapi
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/transport/memory"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/utils"
+	"github.com/google/uuid"
+)
+
+const (
+	// HealthPath is the path of the health endpoint.
+	HealthPath = "/v1/health"
+	// AccountsPath is the path of the accounts collection.
+	AccountsPath = "/v1/organisation/accounts"
+
+	contentType = "application/vnd.api+json"
+)
+
+// ErrorBody is the body of the error responses, as sent by the API.
+type ErrorBody struct {
+	ErrorMessage string `json:"error_message"`
+}
+
+// Server is an http.Handler faking the accounts API.
+type Server struct {
+	// Store holds the accounts served, tests can seed it and inspect it.
+	Store *memory.Transport
+}
+
+// New creates a Server backed by store, or by an empty store when store is nil.
+func New(store *memory.Transport) *Server {
+	if store == nil {
+		store = memory.New()
+	}
+
+	return &Server{Store: store}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch path := strings.TrimSuffix(r.URL.Path, "/"); {
+	case path == HealthPath:
+		s.health(w, r)
+	case path == AccountsPath:
+		s.accounts(w, r)
+	case strings.HasPrefix(path, AccountsPath+"/") && !strings.Contains(path[len(AccountsPath)+1:], "/"):
+		s.account(w, r, path[len(AccountsPath)+1:])
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no route for %s", r.URL.Path))
+	}
+}
+
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "up"})
+}
+
+func (s *Server) accounts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var req utils.CreateAccountRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+
+			return
+		}
+
+		if err := checkUUID("id", req.Data.ID); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+
+			return
+		}
+
+		if err := checkUUID("organisation_id", req.Data.OrganisationID); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+
+			return
+		}
+
+		resp, err := s.Store.Create(r.Context(), &req)
+		if err != nil {
+			writeStoreError(w, err, req.Data.ID)
+
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, resp)
+	case http.MethodGet:
+		req, err := listRequest(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+
+			return
+		}
+
+		resp, err := s.Store.List(r.Context(), req)
+		if err != nil {
+			writeStoreError(w, err, "")
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (s *Server) account(w http.ResponseWriter, r *http.Request, id string) {
+	if err := checkUUID("id", id); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		resp, err := s.Store.Fetch(r.Context(), id)
+		if err != nil {
+			writeStoreError(w, err, id)
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	case http.MethodPatch:
+		var req utils.UpdateAccountRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+
+			return
+		}
+
+		if req.Data.ID != id {
+			writeError(w, http.StatusBadRequest, "id in body does not match the id in the path")
+
+			return
+		}
+
+		resp, err := s.Store.Update(r.Context(), &req)
+		if err != nil {
+			writeStoreError(w, err, id)
+
+			return
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	case http.MethodDelete:
+		version, err := strconv.ParseInt(r.URL.Query().Get("version"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid version number")
+
+			return
+		}
+
+		if err := s.Store.Delete(r.Context(), &utils.DeleteAccountRequest{ID: id, Version: version}); err != nil {
+			writeStoreError(w, err, id)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPatch, http.MethodDelete)
+	}
+}
+
+func listRequest(r *http.Request) (*utils.ListAccountsRequest, error) {
+	req := &utils.ListAccountsRequest{}
+
+	for key, values := range r.URL.Query() {
+		var err error
+
+		switch {
+		case key == "page[number]":
+			req.PageNumber, err = pageParam(key, values[0])
+		case key == "page[size]":
+			req.PageSize, err = pageParam(key, values[0])
+		case strings.HasPrefix(key, "filter[") && strings.HasSuffix(key, "]"):
+			if !req.Filter.Set(key[len("filter["):len(key)-1], values[0]) {
+				err = fmt.Errorf("unsupported %s", key)
+			}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return req, nil
+}
+
+func pageParam(key, value string) (int, error) {
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", key)
+	}
+
+	return number, nil
+}
+
+func checkUUID(field, value string) error {
+	if _, err := uuid.Parse(value); err != nil {
+		return fmt.Errorf("%s is not a valid uuid", field)
+	}
+
+	return nil
+}
+
+// writeStoreError answers with the status the API uses for an error of the store.
+func writeStoreError(w http.ResponseWriter, err error, id string) {
+	var (
+		badRequest *errors.ErrBadRequest
+		notFound   *errors.ErrNotFound
+		conflict   *errors.ErrConflict
+	)
+
+	switch {
+	case stderrors.As(err, &badRequest):
+		writeError(w, http.StatusBadRequest, badRequest.Detail)
+	case stderrors.As(err, &notFound):
+		writeError(w, http.StatusNotFound, fmt.Sprintf("record %s does not exist", id))
+	case stderrors.As(err, &conflict):
+		writeError(w, http.StatusConflict, conflict.Detail)
+	case stderrors.Is(err, context.Canceled):
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, ErrorBody{ErrorMessage: message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body) //nolint:errcheck,errchkjson
+}
@@ -0,0 +1,173 @@
+package fakeapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aabri-assignments/form3-accounts/v1"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/errors"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/models"
+	"github.com/aabri-assignments/form3-accounts/v1/accounts/utils"
+	"github.com/aabri-assignments/form3-accounts/v1/pkg/fakeapi"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccount(country string) *models.AccountData {
+	return &models.AccountData{
+		ID:             uuid.NewString(),
+		OrganisationID: uuid.NewString(),
+		Type:           "accounts",
+		Attributes: &models.AccountAttributes{
+			Country:       &country,
+			BankID:        "400300",
+			BankIDCode:    "GBDSC",
+			Bic:           "NWBKGB22",
+			AccountNumber: "41426819",
+			Name:          []string{"Samantha Holder"},
+		},
+	}
+}
+
+func newClient(t *testing.T) (accounts.Client, *fakeapi.Server, *httptest.Server) {
+	t.Helper()
+
+	api := fakeapi.New(nil)
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	return accounts.New(accounts.Options{BaseURL: server.URL, Retries: 1}), api, server
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Creates, fetches, updates and deletes an account", func(t *testing.T) {
+		client, api, _ := newClient(t)
+		account := newAccount("GB")
+
+		created, err := client.Create(account)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), *created.Version)
+
+		fetched, err := client.Fetch(account.ID)
+		require.NoError(t, err)
+		assert.Equal(t, created, fetched)
+
+		updated, err := client.Update(account.ID, 0, &models.AccountAttributesUpdate{Name: []string{"Sam Holder"}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), *updated.Version)
+
+		var conflict *errors.ErrConflict
+		assert.ErrorAs(t, client.Delete(account.ID, 0), &conflict)
+		assert.NoError(t, client.Delete(account.ID, 1))
+
+		_, ok := api.Store.Account(account.ID)
+		assert.False(t, ok)
+	})
+	t.Run("Rejects duplicate IDs", func(t *testing.T) {
+		client, _, _ := newClient(t)
+		account := newAccount("GB")
+
+		_, err := client.Create(account)
+		require.NoError(t, err)
+		_, err = client.Create(account)
+
+		var conflict *errors.ErrConflict
+		assert.ErrorAs(t, err, &conflict)
+	})
+	t.Run("Returns ErrNotFound for unknown accounts", func(t *testing.T) {
+		client, _, _ := newClient(t)
+
+		_, err := client.Fetch(uuid.NewString())
+		var notFound *errors.ErrNotFound
+		assert.ErrorAs(t, err, &notFound)
+		assert.ErrorAs(t, client.Delete(uuid.NewString(), 0), &notFound)
+	})
+	t.Run("Lists accounts page by page with filters", func(t *testing.T) {
+		client, api, _ := newClient(t)
+		for i := 0; i < 7; i++ {
+			country := "GB"
+			if i%3 == 0 {
+				country = "FR"
+			}
+
+			api.Store.Seed(*newAccount(country))
+		}
+
+		var all, british []string
+
+		require.NoError(t, client.Walk(ctx, &utils.ListAccountsRequest{PageSize: 2}, func(account *models.AccountData) error {
+			all = append(all, account.ID)
+
+			return nil
+		}))
+
+		req := &utils.ListAccountsRequest{PageSize: 2}
+		require.NoError(t, accounts.Filter{Country: "GB"}.Apply(req))
+		require.NoError(t, client.Walk(ctx, req, func(account *models.AccountData) error {
+			british = append(british, account.ID)
+
+			return nil
+		}))
+
+		assert.Len(t, all, 7)
+		assert.Len(t, british, 4)
+	})
+	t.Run("Reports its health", func(t *testing.T) {
+		_, _, server := newClient(t)
+
+		form3Client, err := form3.NewForm3(accounts.Options{BaseURL: server.URL})
+		require.NoError(t, err)
+
+		status, err := form3Client.Health(ctx)
+		require.NoError(t, err)
+		assert.True(t, status.IsUp())
+	})
+}
+
+func TestServerErrors(t *testing.T) {
+	_, _, server := newClient(t)
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		statusCode int
+		message    string
+	}{
+		{"Invalid body", http.MethodPost, fakeapi.AccountsPath, "{", http.StatusBadRequest, "invalid request body"},
+		{"Invalid ID", http.MethodPost, fakeapi.AccountsPath, `{"data": {"id": "invalid"}}`, http.StatusBadRequest, "id is not a valid uuid"},
+		{"Missing attributes", http.MethodPost, fakeapi.AccountsPath, `{"data": {"id": "` + uuid.NewString() + `", "organisation_id": "` + uuid.NewString() + `", "type": "accounts"}}`, http.StatusBadRequest, "attributes"},
+		{"Invalid account ID", http.MethodGet, fakeapi.AccountsPath + "/invalid-id", "", http.StatusBadRequest, "id is not a valid uuid"},
+		{"Missing version", http.MethodDelete, fakeapi.AccountsPath + "/" + uuid.NewString(), "", http.StatusBadRequest, "invalid version number"},
+		{"Invalid page", http.MethodGet, fakeapi.AccountsPath + "?page[size]=-1", "", http.StatusBadRequest, "page[size]"},
+		{"Unsupported filter", http.MethodGet, fakeapi.AccountsPath + "?filter[colour]=red", "", http.StatusBadRequest, "filter[colour]"},
+		{"Unknown route", http.MethodGet, "/v1/organisation/payments", "", http.StatusNotFound, "no route"},
+		{"Method not allowed", http.MethodPut, fakeapi.AccountsPath, "", http.StatusMethodNotAllowed, "method not allowed"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, server.URL+tc.path, strings.NewReader(tc.body))
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			var body fakeapi.ErrorBody
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.Equal(t, tc.statusCode, resp.StatusCode)
+			assert.Equal(t, "application/vnd.api+json", resp.Header.Get("Content-Type"))
+			assert.Contains(t, body.ErrorMessage, tc.message)
+		})
+	}
+}
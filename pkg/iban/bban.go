@@ -0,0 +1,152 @@
+package iban
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// buildBBAN assembles the national part of the IBAN from Form3 bank details, adding the national
+// check digits for the countries that have them.
+func buildBBAN(country, bankID, bic, accountNumber string) (string, error) {
+	switch country {
+	case "GB", "NL":
+		if len(bic) < 4 {
+			return "", fmt.Errorf("%w: %s IBANs need the bank code of the BIC", ErrInvalidBBAN, country)
+		}
+
+		if country == "NL" {
+			return bic[:4] + pad(accountNumber, 10), nil
+		}
+
+		return bic[:4] + bankID + accountNumber, nil
+	case "BE":
+		return bankID + pad(accountNumber, 7) + belgianCheck(bankID+pad(accountNumber, 7)), nil
+	case "CH":
+		return bankID + pad(accountNumber, 12), nil
+	case "DE":
+		return bankID + pad(accountNumber, 10), nil
+	case "ES":
+		return bankID + spanishCheck(bankID, pad(accountNumber, 10)) + pad(accountNumber, 10), nil
+	case "FR":
+		return bankID + pad(accountNumber, 11) + ribKey(bankID, pad(accountNumber, 11)), nil
+	case "GR":
+		return bankID + pad(accountNumber, 16), nil
+	case "IT":
+		return italianCIN(bankID+pad(accountNumber, 12)) + bankID + pad(accountNumber, 12), nil
+	case "LU":
+		return bankID + pad(accountNumber, 13), nil
+	case "PL":
+		return bankID + accountNumber, nil
+	case "PT":
+		return bankID + pad(accountNumber, 11) + CheckDigits("", bankID+pad(accountNumber, 11)), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedCountry, country)
+	}
+}
+
+// pad left-pads an account number with zeros up to the given length.
+func pad(s string, length int) string {
+	if len(s) >= length {
+		return s
+	}
+
+	return strings.Repeat("0", length-len(s)) + s
+}
+
+// belgianCheck computes the two check digits of a Belgian account, the remainder modulo 97 or 97 when it is zero.
+func belgianCheck(digits string) string {
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return ""
+	}
+
+	check := n % 97
+	if check == 0 {
+		check = 97
+	}
+
+	return fmt.Sprintf("%02d", check)
+}
+
+// spanishCheck computes the two "dígitos de control" of a Spanish bank, branch and account number.
+func spanishCheck(bankID, accountNumber string) string {
+	weights := []int{1, 2, 4, 8, 5, 10, 9, 7, 3, 6}
+
+	digit := func(s string) string {
+		sum := 0
+
+		for i, r := range s {
+			if i >= len(weights) || r < '0' || r > '9' {
+				return ""
+			}
+
+			sum += int(r-'0') * weights[i]
+		}
+
+		check := 11 - sum%11
+
+		switch check {
+		case 11:
+			check = 0
+		case 10:
+			check = 1
+		}
+
+		return strconv.Itoa(check)
+	}
+
+	return digit("00"+bankID) + digit(accountNumber)
+}
+
+// ribKey computes the French "clé RIB" of a bank, branch and account number.
+func ribKey(bankID, accountNumber string) string {
+	if len(bankID) != 10 {
+		return ""
+	}
+
+	converted := strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' {
+			return '1' + (r-'A')%9 + (r-'A')/18
+		}
+
+		return r
+	}, accountNumber)
+
+	bank, errBank := strconv.ParseInt(bankID[:5], 10, 64)
+	branch, errBranch := strconv.ParseInt(bankID[5:], 10, 64)
+	account, errAccount := strconv.ParseInt(converted, 10, 64)
+
+	if errBank != nil || errBranch != nil || errAccount != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%02d", 97-(89*bank+15*branch+3*account)%97)
+}
+
+// italianCIN computes the Italian "CIN" control letter of an ABI, CAB and account number.
+func italianCIN(s string) string {
+	odd := []int{1, 0, 5, 7, 9, 13, 15, 17, 19, 21, 2, 4, 18, 20, 11, 3, 6, 8, 12, 14, 16, 10, 22, 25, 24, 23}
+	sum := 0
+
+	for i, r := range s {
+		var value int
+
+		switch {
+		case r >= '0' && r <= '9':
+			value = int(r - '0')
+		case r >= 'A' && r <= 'Z':
+			value = int(r - 'A')
+		default:
+			return ""
+		}
+
+		if i%2 == 0 {
+			sum += odd[value]
+		} else {
+			sum += value
+		}
+	}
+
+	return string(rune('A' + sum%26))
+}
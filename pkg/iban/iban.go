@@ -0,0 +1,133 @@
+// Package iban validates International Bank Account Numbers and generates them from national bank details.
+package iban
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrUnsupportedCountry is returned for countries that do not use IBANs or cannot be generated.
+	ErrUnsupportedCountry = errors.New("unsupported country")
+	// ErrInvalidFormat is returned when an IBAN contains characters other than upper-case letters and digits.
+	ErrInvalidFormat = errors.New("invalid format")
+	// ErrInvalidLength is returned when an IBAN does not have the length registered for its country.
+	ErrInvalidLength = errors.New("invalid length")
+	// ErrInvalidChecksum is returned when the mod-97 check digits of an IBAN do not match.
+	ErrInvalidChecksum = errors.New("invalid check digits")
+	// ErrInvalidBBAN is returned when the national part of an IBAN does not follow the structure of its country.
+	ErrInvalidBBAN = errors.New("invalid BBAN")
+)
+
+var ibanFormat = regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z0-9]+$`)
+
+// Lengths is the IBAN length of every country in the SWIFT IBAN registry.
+var Lengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16, "BG": 22, "BH": 22, "BR": 29,
+	"BY": 28, "CH": 21, "CR": 22, "CY": 28, "CZ": 24, "DE": 22, "DK": 18, "DO": 28, "EE": 20, "EG": 29,
+	"ES": 24, "FI": 18, "FO": 18, "FR": 27, "GB": 22, "GE": 22, "GI": 23, "GL": 18, "GR": 27, "GT": 28,
+	"HR": 21, "HU": 28, "IE": 22, "IL": 23, "IQ": 23, "IS": 26, "IT": 27, "JO": 30, "KW": 30, "KZ": 20,
+	"LB": 28, "LC": 32, "LI": 21, "LT": 20, "LU": 20, "LV": 21, "MC": 27, "MD": 24, "ME": 22, "MK": 19,
+	"MR": 27, "MT": 31, "MU": 30, "NL": 18, "NO": 15, "PK": 24, "PL": 28, "PS": 29, "PT": 25, "QA": 29,
+	"RO": 24, "RS": 22, "SA": 24, "SC": 31, "SE": 24, "SI": 19, "SK": 24, "SM": 27, "ST": 25, "SV": 28,
+	"TL": 23, "TN": 24, "TR": 26, "UA": 29, "VA": 22, "VG": 24, "XK": 20,
+}
+
+// bbanFormats describes the national part of the IBAN for the countries Form3 supports.
+var bbanFormats = map[string]*regexp.Regexp{
+	"BE": regexp.MustCompile(`^\d{3}\d{7}\d{2}$`),
+	"CH": regexp.MustCompile(`^\d{5}[A-Z0-9]{12}$`),
+	"DE": regexp.MustCompile(`^\d{8}\d{10}$`),
+	"ES": regexp.MustCompile(`^\d{4}\d{4}\d{2}\d{10}$`),
+	"FR": regexp.MustCompile(`^\d{5}\d{5}[A-Z0-9]{11}\d{2}$`),
+	"GB": regexp.MustCompile(`^[A-Z]{4}\d{6}\d{8}$`),
+	"GR": regexp.MustCompile(`^\d{3}\d{4}[A-Z0-9]{16}$`),
+	"IT": regexp.MustCompile(`^[A-Z]\d{5}\d{5}[A-Z0-9]{12}$`),
+	"LU": regexp.MustCompile(`^\d{3}[A-Z0-9]{13}$`),
+	"NL": regexp.MustCompile(`^[A-Z]{4}\d{10}$`),
+	"PL": regexp.MustCompile(`^\d{8}\d{16}$`),
+	"PT": regexp.MustCompile(`^\d{4}\d{4}\d{11}\d{2}$`),
+}
+
+// Normalize converts an IBAN in print format, with spaces and lower-case letters, to electronic format.
+func Normalize(iban string) string {
+	return strings.ToUpper(strings.ReplaceAll(iban, " ", ""))
+}
+
+// Validate checks the format, the country length, the mod-97 check digits and, for the countries
+// Form3 supports, the BBAN structure of an IBAN in electronic format.
+func Validate(iban string) error {
+	if !ibanFormat.MatchString(iban) {
+		return fmt.Errorf("%w: %q", ErrInvalidFormat, iban)
+	}
+
+	country := iban[:2]
+
+	length, ok := Lengths[country]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedCountry, country)
+	}
+
+	if len(iban) != length {
+		return fmt.Errorf("%w: %s IBANs have %d characters, got %d", ErrInvalidLength, country, length, len(iban))
+	}
+
+	if mod97(iban[4:]+iban[:4]) != 1 {
+		return fmt.Errorf("%w: %q", ErrInvalidChecksum, iban)
+	}
+
+	if format, ok := bbanFormats[country]; ok && !format.MatchString(iban[4:]) {
+		return fmt.Errorf("%w: %q", ErrInvalidBBAN, iban[4:])
+	}
+
+	return nil
+}
+
+// IsValid reports whether Validate accepts the IBAN.
+func IsValid(iban string) bool {
+	return Validate(iban) == nil
+}
+
+// Generate builds the IBAN of an account from its Form3 bank details. The BIC is only used by
+// countries whose IBAN carries the bank code of the BIC, such as GB and NL.
+func Generate(country, bankID, bic, accountNumber string) (string, error) {
+	bban, err := buildBBAN(country, strings.ToUpper(bankID), strings.ToUpper(bic), strings.ToUpper(accountNumber))
+	if err != nil {
+		return "", err
+	}
+
+	if format, ok := bbanFormats[country]; !ok || !format.MatchString(bban) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidBBAN, bban)
+	}
+
+	return country + CheckDigits(country, bban) + bban, nil
+}
+
+// CheckDigits computes the two mod-97 check digits of the IBAN made of the country and the BBAN.
+func CheckDigits(country, bban string) string {
+	return fmt.Sprintf("%02d", 98-mod97(bban+country+"00"))
+}
+
+// mod97 returns the remainder of the division by 97 of the number made of the digits of s,
+// where letters are replaced by two digits (A = 10, ..., Z = 35).
+func mod97(s string) int {
+	var digits strings.Builder
+
+	for _, r := range s {
+		if r >= 'A' && r <= 'Z' {
+			fmt.Fprintf(&digits, "%d", r-'A'+10)
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	if !ok {
+		return -1
+	}
+
+	return int(new(big.Int).Mod(n, big.NewInt(97)).Int64())
+}
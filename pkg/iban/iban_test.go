@@ -0,0 +1,101 @@
+package iban_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aabri-assignments/form3-accounts/v1/pkg/iban"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run("Accepts valid IBANs", func(t *testing.T) {
+		for _, valid := range []string{
+			"BE68539007547034",
+			"CH9300762011623852957",
+			"DE89370400440532013000",
+			"ES9121000418450200051332",
+			"FR1420041010050500013M02606",
+			"GB29NWBK60161331926819",
+			"GR1601101250000000012300695",
+			"IT60X0542811101000000123456",
+			"LU280019400644750000",
+			"NL91ABNA0417164300",
+			"PL61109010140000071219812874",
+			"PT50000201231234567890154",
+			"NO9386011117947",
+		} {
+			assert.NoError(t, iban.Validate(valid), valid)
+			assert.True(t, iban.IsValid(valid), valid)
+		}
+	})
+
+	testCases := []struct {
+		name        string
+		iban        string
+		expectedErr error
+	}{
+		{name: "lower case", iban: "gb29nwbk60161331926819", expectedErr: iban.ErrInvalidFormat},
+		{name: "not an IBAN", iban: "invalid", expectedErr: iban.ErrInvalidFormat},
+		{name: "unknown country", iban: "US29NWBK60161331926819", expectedErr: iban.ErrUnsupportedCountry},
+		{name: "too short", iban: "GB29NWBK6016133192681", expectedErr: iban.ErrInvalidLength},
+		{name: "wrong check digits", iban: "GB28NWBK60161331926819", expectedErr: iban.ErrInvalidChecksum},
+		{name: "invalid BBAN structure", iban: "GB58123460161331926819", expectedErr: iban.ErrInvalidBBAN},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := iban.Validate(tc.iban)
+			assert.True(t, errors.Is(err, tc.expectedErr), "Expected %v, got %v", tc.expectedErr, err)
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "GB29NWBK60161331926819", iban.Normalize("gb29 nwbk 6016 1331 9268 19"))
+}
+
+func TestGenerate(t *testing.T) {
+	testCases := []struct {
+		country       string
+		bankID        string
+		bic           string
+		accountNumber string
+		expected      string
+	}{
+		{country: "BE", bankID: "539", accountNumber: "0075470", expected: "BE68539007547034"},
+		{country: "CH", bankID: "00762", accountNumber: "011623852957", expected: "CH9300762011623852957"},
+		{country: "DE", bankID: "37040044", accountNumber: "532013000", expected: "DE89370400440532013000"},
+		{country: "ES", bankID: "21000418", accountNumber: "0200051332", expected: "ES9121000418450200051332"},
+		{country: "FR", bankID: "2004101005", accountNumber: "0500013M026", expected: "FR1420041010050500013M02606"},
+		{country: "GB", bankID: "601613", bic: "NWBKGB2L", accountNumber: "31926819", expected: "GB29NWBK60161331926819"},
+		{country: "GR", bankID: "0110125", accountNumber: "0000000012300695", expected: "GR1601101250000000012300695"},
+		{country: "IT", bankID: "0542811101", accountNumber: "000000123456", expected: "IT60X0542811101000000123456"},
+		{country: "LU", bankID: "001", accountNumber: "9400644750000", expected: "LU280019400644750000"},
+		{country: "NL", bic: "ABNANL2A", accountNumber: "417164300", expected: "NL91ABNA0417164300"},
+		{country: "PL", bankID: "10901014", accountNumber: "0000071219812874", expected: "PL61109010140000071219812874"},
+		{country: "PT", bankID: "00020123", accountNumber: "12345678901", expected: "PT50000201231234567890154"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.country, func(t *testing.T) {
+			generated, err := iban.Generate(tc.country, tc.bankID, tc.bic, tc.accountNumber)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, generated)
+			assert.NoError(t, iban.Validate(generated))
+		})
+	}
+
+	t.Run("Fails for countries without IBANs", func(t *testing.T) {
+		_, err := iban.Generate("US", "021000021", "CHASUS33", "123456789")
+		assert.True(t, errors.Is(err, iban.ErrUnsupportedCountry))
+	})
+	t.Run("Fails without the bank code of the BIC", func(t *testing.T) {
+		_, err := iban.Generate("GB", "601613", "", "31926819")
+		assert.True(t, errors.Is(err, iban.ErrInvalidBBAN))
+	})
+	t.Run("Fails with invalid bank details", func(t *testing.T) {
+		_, err := iban.Generate("GB", "6016", "NWBKGB2L", "31926819")
+		assert.True(t, errors.Is(err, iban.ErrInvalidBBAN))
+	})
+}